@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// branchColumn is a named column of the branch table. Columns that need extra git calls
+// do them in load, which only runs for the columns that are shown.
+type branchColumn struct {
+	Name     string
+	Title    string
+	Width    int
+	MinWidth int                                     // Narrowest the column may shrink to on small terminals
+	Priority int                                     // Lower is more important; the highest are hidden first when space runs out
+	value    func(branch Branch, name string) string // name is the decorated branch name
+	group    func(group *BranchTreeNode) string      // Group header cell in tree mode, nil for blank
+	load     func(g *GitService, branches []Branch) error
+}
+
+// columnRegistry lists every available column
+func columnRegistry(config Config) []*branchColumn {
+	ticketPattern := regexp.MustCompile(config.TicketPattern)
+
+	return []*branchColumn{
+		{
+			Name: "branch", Title: "Branch", Width: 35, MinWidth: 15, Priority: 0,
+			value: func(branch Branch, name string) string { return name },
+		},
+		{
+			Name: "last-used", Title: "Last Used", Width: 15, MinWidth: 12, Priority: 1,
+			value: func(branch Branch, name string) string { return formatLastUsedTime(branch.LastUsed) },
+			group: func(group *BranchTreeNode) string { return formatLastUsedTime(group.LastUsed) },
+		},
+		{
+			Name: "commit-date", Title: "Last Commit", Width: 12, MinWidth: 10, Priority: 4,
+			value: func(branch Branch, name string) string { return branch.CommitDate.Format("2006-01-02") },
+			group: func(group *BranchTreeNode) string { return group.CommitDate.Format("2006-01-02") },
+		},
+		{
+			Name: "title", Title: "Commit Message", Width: 60, MinWidth: 20, Priority: 2,
+			value: func(branch Branch, name string) string {
+				// Mention who pushed since the last background fetch
+				if len(branch.UpdatedBy) > 0 {
+					return "(pushed by " + strings.Join(branch.UpdatedBy, ", ") + ") " + branch.CommitTitle
+				}
+				return branch.CommitTitle
+			},
+			group: func(group *BranchTreeNode) string {
+				if group.Count == 1 {
+					return "1 branch"
+				}
+				return fmt.Sprintf("%d branches", group.Count)
+			},
+		},
+		{
+			Name: "author", Title: "Author", Width: 18, MinWidth: 8, Priority: 6,
+			value: func(branch Branch, name string) string { return branch.Author },
+		},
+		{
+			Name: "upstream", Title: "Upstream", Width: 30, MinWidth: 12, Priority: 5,
+			value: formatUpstream,
+			load:  (*GitService).fillUpstreams,
+		},
+		{
+			Name: "ahead-behind", Title: "Base +/-", Width: 10, MinWidth: 8, Priority: 5,
+			value: func(branch Branch, name string) string {
+				return fmt.Sprintf("+%d -%d", branch.AheadCount, branch.BehindCount)
+			},
+			load: func(g *GitService, branches []Branch) error {
+				g.fillAheadBehind(branches)
+				return nil
+			},
+		},
+		{
+			Name: "pr", Title: "PR", Width: 7, MinWidth: 5, Priority: 3,
+			value: func(branch Branch, name string) string {
+				if branch.PullRequest == 0 {
+					return ""
+				}
+				return fmt.Sprintf("#%d", branch.PullRequest)
+			},
+			load: (*GitService).fillPullRequests,
+		},
+		{
+			Name: "ticket", Title: "Ticket", Width: 12, MinWidth: 8, Priority: 3,
+			value: func(branch Branch, name string) string {
+				return ticketPattern.FindString(branchPath(branch))
+			},
+		},
+		{
+			Name: "description", Title: "Description", Width: 40, MinWidth: 15, Priority: 7,
+			value: func(branch Branch, name string) string { return branch.Description },
+			load:  (*GitService).fillDescriptions,
+		},
+	}
+}
+
+// resolveColumns returns the configured columns in order
+func resolveColumns(config Config) ([]*branchColumn, error) {
+	if _, err := regexp.Compile(config.TicketPattern); err != nil {
+		return nil, fmt.Errorf("invalid ticket_pattern: %v", err)
+	}
+
+	registry := columnRegistry(config)
+	var names []string
+	for _, column := range registry {
+		names = append(names, column.Name)
+	}
+
+	var columns []*branchColumn
+	seen := make(map[string]bool)
+	for _, name := range config.Columns {
+		if seen[name] {
+			return nil, fmt.Errorf("column %q is listed twice", name)
+		}
+		seen[name] = true
+
+		var found *branchColumn
+		for _, column := range registry {
+			if column.Name == name {
+				found = column
+				break
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("unknown column %q (expected one of %s)", name, strings.Join(names, ", "))
+		}
+		columns = append(columns, found)
+	}
+
+	if !seen["branch"] {
+		return nil, fmt.Errorf("columns must include \"branch\"")
+	}
+	return columns, nil
+}
+
+// formatUpstream summarizes the upstream branch and how far the branch is from it
+func formatUpstream(branch Branch, name string) string {
+	switch {
+	case branch.IsRemote:
+		return ""
+	case branch.Upstream == "":
+		return "-"
+	case branch.Track == "gone":
+		return branch.Upstream + " (gone)"
+	case branch.Track == "":
+		return branch.Upstream + " ✓"
+	}
+
+	track := strings.NewReplacer("ahead ", "↑", "behind ", "↓", ",", "").Replace(branch.Track)
+	return branch.Upstream + " " + track
+}
+
+// fillUpstreams looks up the upstream of every local branch in one for-each-ref call
+func (g *GitService) fillUpstreams(branches []Branch) error {
+	cmd := gitCommand("for-each-ref",
+		"--format=%(refname:short)|%(upstream:short)|%(upstream:track,nobracket)",
+		"refs/heads/")
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to get upstream branches: %v", err)
+	}
+
+	upstreams := make(map[string][2]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) == 3 {
+			upstreams[parts[0]] = [2]string{parts[1], parts[2]}
+		}
+	}
+
+	for i, branch := range branches {
+		if upstream, ok := upstreams[branch.Name]; ok && !branch.IsRemote {
+			branches[i].Upstream = upstream[0]
+			branches[i].Track = upstream[1]
+		}
+	}
+	return nil
+}
+
+// fillPullRequests matches branches to open pull requests using the GitHub CLI
+func (g *GitService) fillPullRequests(branches []Branch) error {
+	cmd := exec.Command("gh", "pr", "list", "--state", "open", "--limit", "200", "--json", "number,headRefName")
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to list pull requests with gh: %v", err)
+	}
+
+	var pullRequests []struct {
+		Number      int    `json:"number"`
+		HeadRefName string `json:"headRefName"`
+	}
+	if err := json.Unmarshal(output, &pullRequests); err != nil {
+		return fmt.Errorf("failed to parse gh output: %v", err)
+	}
+
+	numbers := make(map[string]int)
+	for _, pr := range pullRequests {
+		numbers[pr.HeadRefName] = pr.Number
+	}
+	for i, branch := range branches {
+		branches[i].PullRequest = numbers[branchPath(branch)]
+	}
+	return nil
+}
+
+// fillDescriptions reads branch.<name>.description for every branch in one git config call
+func (g *GitService) fillDescriptions(branches []Branch) error {
+	cmd := gitCommand("config", "-z", "--get-regexp", `^branch\..*\.description$`)
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit status 1 just means no branch has a description
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return nil
+		}
+		return fmt.Errorf("failed to read branch descriptions: %v", err)
+	}
+
+	descriptions := make(map[string]string)
+	for _, entry := range strings.Split(string(output), "\x00") {
+		key, value, _ := strings.Cut(entry, "\n")
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "branch."), ".description")
+		if name != key {
+			descriptions[name], _, _ = strings.Cut(strings.TrimSpace(value), "\n")
+		}
+	}
+
+	for i, branch := range branches {
+		if !branch.IsRemote {
+			branches[i].Description = descriptions[branch.Name]
+		}
+	}
+	return nil
+}
+
+// loadColumnData runs the git calls needed by the shown columns for freshly loaded branches
+func (m *model) loadColumnData() {
+	m.tableManager.loadedColumns = make(map[string]bool)
+	// Sorting by ahead already counted them while loading the branches
+	if m.sortOrder.Key == SortAhead {
+		m.tableManager.loadedColumns["ahead-behind"] = true
+	}
+	m.loadMissingColumnData()
+}
+
+// loadMissingColumnData loads the shown columns that haven't been loaded for the current
+// branches, e.g. after the terminal got wider. Reports whether anything was loaded.
+func (m *model) loadMissingColumnData() bool {
+	// Wait for the terminal size to know which columns fit
+	if m.tableManager.width == 0 {
+		return false
+	}
+
+	loaded := false
+	visible, _ := fitColumns(m.tableManager.columns, m.tableManager.width)
+	for _, column := range visible {
+		if column.load == nil || m.tableManager.loadedColumns[column.Name] {
+			continue
+		}
+		m.tableManager.loadedColumns[column.Name] = true
+		loaded = true
+		if err := column.load(m.gitService, m.branches); err != nil {
+			m.logError("Failed to load %s column: %v", column.Name, err)
+		}
+	}
+	return loaded
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLoadColumnDataLoadsOnlyVisibleColumns(t *testing.T) {
+	var loads []string
+	column := func(name string, priority int) *branchColumn {
+		return &branchColumn{
+			Name: name, Title: name, Width: 20, MinWidth: 20, Priority: priority,
+			value: func(branch Branch, name string) string { return "" },
+			load: func(g *GitService, branches []Branch) error {
+				loads = append(loads, name)
+				return nil
+			},
+		}
+	}
+	m := &model{
+		tableManager: NewTableManager([]*branchColumn{column("name", 0), column("extra", 5)}, DefaultSortOrder),
+		branches:     []Branch{{Name: "main"}},
+	}
+
+	// Nothing is known to fit before the terminal size arrives
+	m.loadColumnData()
+	if len(loads) != 0 {
+		t.Fatalf("loaded %v without a width", loads)
+	}
+
+	m.tableManager.SetWidth(30)
+	m.loadMissingColumnData()
+	if !slices.Equal(loads, []string{"name"}) {
+		t.Fatalf("narrow terminal loaded %v", loads)
+	}
+
+	// Widening shows and loads the hidden column once
+	m.tableManager.SetWidth(100)
+	m.loadMissingColumnData()
+	m.loadMissingColumnData()
+	if !slices.Equal(loads, []string{"name", "extra"}) {
+		t.Errorf("wide terminal loaded %v", loads)
+	}
+}
+
+func TestLoadColumnDataSkipsAheadBehindWhenSortedByAhead(t *testing.T) {
+	loads := 0
+	aheadBehind := &branchColumn{
+		Name: "ahead-behind", Title: "Base +/-", Width: 10, MinWidth: 8,
+		value: func(branch Branch, name string) string { return "" },
+		load: func(g *GitService, branches []Branch) error {
+			loads++
+			return nil
+		},
+	}
+	m := &model{
+		tableManager: NewTableManager([]*branchColumn{aheadBehind}, DefaultSortOrder),
+		branches:     []Branch{{Name: "main"}},
+	}
+	m.tableManager.SetWidth(80)
+
+	m.sortOrder = SortOrder{Key: SortAhead, Descending: true}
+	m.loadColumnData()
+	if loads != 0 {
+		t.Errorf("counted ahead/behind again after sorting by ahead")
+	}
+
+	m.sortOrder = DefaultSortOrder
+	m.loadColumnData()
+	if loads != 1 {
+		t.Errorf("loaded the column %d times, want 1", loads)
+	}
+}
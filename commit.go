@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commitOutputMsg carries one line of output from git commit and its hooks
+type commitOutputMsg struct {
+	line string
+}
+
+// commitDoneMsg is sent once a commit started from the modal has finished
+type commitDoneMsg struct {
+	branch     string // Branch to switch to once committed
+	options    CommitOptions
+	autosquash *Commit // Commit to squash the fixup into, if chosen
+	err        error
+}
+
+// startCommit commits in the background, streaming hook output into the commit modal,
+// which stays open until the commit succeeds
+func (m *model) startCommit(targetBranch string, noVerify bool) tea.Cmd {
+	subject, description := m.commitModal.GetCommitMessage()
+	options := m.commitModal.GetCommitOptions(noVerify)
+	var autosquash *Commit
+	if target, ok := m.commitModal.GetAutosquashTarget(); ok {
+		autosquash = &target
+	}
+	switch {
+	case options.FixupOf != "":
+		m.logInfo("User chose to fix up commit %s", options.FixupOf[:8])
+	case options.Amend:
+		m.logInfo("User chose to amend the last commit: '%s'", subject)
+	default:
+		m.logInfo("User chose to commit changes: '%s'", subject)
+		m.logDebug("Committing changes with message: %s", subject)
+	}
+	if noVerify {
+		m.logInfo("Skipping commit hooks (--no-verify)")
+	}
+	m.commitModal.StartCommit()
+
+	events := make(chan tea.Msg, 64)
+	m.commitEvents = events
+	gitService := m.gitService
+
+	go func() {
+		defer close(events)
+		output := func(line string) {
+			events <- commitOutputMsg{line: line}
+		}
+		err := gitService.CommitChanges(subject, description, options, output)
+		events <- commitDoneMsg{branch: targetBranch, options: options, autosquash: autosquash, err: err}
+	}()
+
+	return waitForEvent(events)
+}
+
+// handleCommitMsg shows commit output in the modal and switches branches once the commit is done
+func (m *model) handleCommitMsg(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case commitOutputMsg:
+		m.commitModal.AppendOutput(msg.line)
+		m.logDebug("commit: %s", msg.line)
+		return waitForEvent(m.commitEvents)
+
+	case commitDoneMsg:
+		m.commitEvents = nil
+		if msg.err != nil {
+			// Keep the modal and message so the commit can be retried
+			m.logError("Failed to commit changes: %v", msg.err)
+			m.commitModal.CommitFailed(msg.err)
+			return nil
+		}
+
+		committed := "Committed changes"
+		switch {
+		case msg.options.FixupOf != "":
+			committed = "Made a fixup commit"
+		case msg.options.Amend:
+			committed = "Amended the last commit"
+		}
+		m.logSuccess("%s", committed)
+		if err := m.commitModal.RecordCommit(); err != nil {
+			m.logError("Failed to save commit message history: %v", err)
+		}
+		m.commitModal.Hide()
+
+		if target := msg.autosquash; target != nil {
+			return m.squashFixup(*target, msg.options.Sign, msg.branch)
+		}
+		m.switchAfterCommit(committed, msg.branch)
+	}
+	return nil
+}
+
+// squashFixup squashes a fixup commit into its target in the background, then switches to
+// targetBranch like a plain commit does
+func (m *model) squashFixup(target Commit, sign bool, targetBranch string) tea.Cmd {
+	// The running rebase may own the worktree, so neither squash nor switch under it
+	if m.rebaseEvents != nil {
+		m.logError("Not squashing the fixup into %s or switching to %s while another rebase is running", target.Hash, targetBranch)
+		m.message = "Made a fixup commit, but a rebase is running: not squashed and not switched"
+		return nil
+	}
+
+	m.logInfo("Squashing fixup into %s %s", target.Hash, target.Subject)
+	m.message = fmt.Sprintf("Squashing fixup into %s...", target.Hash)
+	branch, _ := m.gitService.GetCurrentBranch()
+	events := make(chan tea.Msg, 64)
+	m.rebaseEvents = events
+	gitService := m.gitService
+
+	go func() {
+		defer close(events)
+		progress := func(line string) {
+			events <- rebaseProgressMsg{line: line}
+		}
+		err := gitService.AutosquashFixups(target.FullHash, sign, progress)
+		events <- rebaseDoneMsg{branch: branch, previousBranch: branch, fixup: &target, switchTo: targetBranch, err: err}
+	}()
+
+	return waitForEvent(events)
+}
+
+// switchAfterCommit switches to the branch the commit modal was opened for
+func (m *model) switchAfterCommit(committed, targetBranch string) {
+	m.logDebug("Now switching to target branch: %s", targetBranch)
+	if err := m.completeSwitch(targetBranch); err != nil {
+		m.logError("Failed to switch to branch after commit: %v", err)
+		m.message = fmt.Sprintf("%s but branch switch failed: %v", committed, err)
+		return
+	}
+	m.logSuccess("Successfully switched to branch: %s", targetBranch)
+	m.message = fmt.Sprintf("%s and switched to: %s", committed, targetBranch)
+	// Refresh branches to show new current branch at top
+	m.logDebug("Refreshing branch list after successful switch")
+	if err := m.loadBranches(); err != nil {
+		m.logError("Failed to refresh branches: %v", err)
+	} else {
+		m.setupTable()
+		m.logDebug("Branch list refreshed successfully")
+	}
+}
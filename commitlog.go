@@ -0,0 +1,394 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CommitLogAction is an action requested from the log view that the main model carries out
+type CommitLogAction int
+
+const (
+	CommitLogActionNone CommitLogAction = iota
+	CommitLogActionCherryPick
+)
+
+// commitLogPageSize is how many commits are fetched from git at a time
+const commitLogPageSize = 50
+
+// CommitLogView is a full-screen, lazily paged commit history for a single branch
+type CommitLogView struct {
+	visible    bool
+	branch     string
+	uniqueOnly bool // Only show commits not reachable from the base branch
+	commits    []Commit
+	cursor     int
+	offset     int
+	height     int
+	exhausted  bool // Git returned fewer commits than requested, nothing more to load
+	err        error
+	showDetail bool
+	detail     viewport.Model
+	selected   map[string]bool // Full hashes marked for cherry-pick
+	action     CommitLogAction
+	gitService *GitService
+
+	// Key bindings
+	keys CommitLogKeyMap
+}
+
+type CommitLogKeyMap struct {
+	Up         key.Binding
+	Down       key.Binding
+	PageUp     key.Binding
+	PageDown   key.Binding
+	ToggleAll  key.Binding
+	Open       key.Binding
+	Back       key.Binding
+	Select     key.Binding
+	CherryPick key.Binding
+}
+
+var commitLogKeys = CommitLogKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "down"),
+	),
+	PageUp: key.NewBinding(
+		key.WithKeys("pgup"),
+		key.WithHelp("pgup", "page up"),
+	),
+	PageDown: key.NewBinding(
+		key.WithKeys("pgdown"),
+		key.WithHelp("pgdown", "page down"),
+	),
+	ToggleAll: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "toggle all history"),
+	),
+	Open: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "show commit"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys("esc", "q"),
+		key.WithHelp("esc", "back"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "select commit"),
+	),
+	CherryPick: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "cherry-pick onto HEAD"),
+	),
+}
+
+func (k *CommitLogKeyMap) named() []namedBinding {
+	return []namedBinding{
+		{"commit-log.up", &k.Up},
+		{"commit-log.down", &k.Down},
+		{"commit-log.page-up", &k.PageUp},
+		{"commit-log.page-down", &k.PageDown},
+		{"commit-log.toggle-all", &k.ToggleAll},
+		{"commit-log.open", &k.Open},
+		{"commit-log.back", &k.Back},
+		{"commit-log.select", &k.Select},
+		{"commit-log.cherry-pick", &k.CherryPick},
+	}
+}
+
+var (
+	commitLogSelectedStyle = lipgloss.NewStyle()
+)
+
+func NewCommitLogView() *CommitLogView {
+	return &CommitLogView{
+		height:     20,
+		detail:     viewport.New(120, 20),
+		gitService: NewGitService(),
+		keys:       commitLogKeys,
+	}
+}
+
+// Show opens the log for a branch, starting with the commits unique to it
+func (v *CommitLogView) Show(branchName string) {
+	v.visible = true
+	v.branch = branchName
+	v.uniqueOnly = true
+	v.showDetail = false
+	v.selected = make(map[string]bool)
+	v.action = CommitLogActionNone
+	v.reload()
+}
+
+func (v *CommitLogView) Hide() {
+	v.visible = false
+	v.showDetail = false
+	v.commits = nil
+	v.action = CommitLogActionNone
+}
+
+func (v *CommitLogView) GetAction() CommitLogAction {
+	return v.action
+}
+
+func (v *CommitLogView) GetBranch() string {
+	return v.branch
+}
+
+// GetPickedCommits returns the selected commits, newest first, or the commit under the cursor if none are selected
+func (v *CommitLogView) GetPickedCommits() []Commit {
+	var picked []Commit
+	for _, commit := range v.commits {
+		if v.selected[commit.FullHash] {
+			picked = append(picked, commit)
+		}
+	}
+	if len(picked) == 0 {
+		if commit, ok := v.SelectedCommit(); ok {
+			picked = append(picked, commit)
+		}
+	}
+	return picked
+}
+
+// SetSize fits the log and the commit detail to the terminal
+func (v *CommitLogView) SetSize(width, height int) {
+	if height > 0 {
+		v.height = max(height-6, 3) // Title, spacer, border, status and help
+		v.detail.Height = max(height-4, 3)
+	}
+	if width > 0 {
+		v.detail.Width = width
+	}
+}
+
+func (v *CommitLogView) IsVisible() bool {
+	return v.visible
+}
+
+// reload discards loaded commits and fetches the first page again
+func (v *CommitLogView) reload() {
+	v.commits = nil
+	v.cursor = 0
+	v.offset = 0
+	v.exhausted = false
+	v.err = nil
+	v.loadMore()
+}
+
+// loadMore appends the next page of commits if there is one
+func (v *CommitLogView) loadMore() {
+	if v.exhausted {
+		return
+	}
+
+	commits, err := v.gitService.GetBranchLog(v.branch, len(v.commits), commitLogPageSize, v.uniqueOnly)
+	if err != nil {
+		v.err = err
+		v.exhausted = true
+		return
+	}
+
+	v.commits = append(v.commits, commits...)
+	if len(commits) < commitLogPageSize {
+		v.exhausted = true
+	}
+}
+
+// moveCursor moves the selection, loading more history when nearing the end
+func (v *CommitLogView) moveCursor(delta int) {
+	if len(v.commits) == 0 {
+		return
+	}
+
+	v.cursor += delta
+	if v.cursor >= len(v.commits)-v.height/2 {
+		v.loadMore()
+	}
+	v.cursor = max(0, min(v.cursor, len(v.commits)-1))
+
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	} else if v.cursor >= v.offset+v.height {
+		v.offset = v.cursor - v.height + 1
+	}
+}
+
+// SelectedCommit returns the commit under the cursor, if any
+func (v *CommitLogView) SelectedCommit() (Commit, bool) {
+	if v.cursor < len(v.commits) {
+		return v.commits[v.cursor], true
+	}
+	return Commit{}, false
+}
+
+func (v *CommitLogView) openDetail() {
+	commit, ok := v.SelectedCommit()
+	if !ok {
+		return
+	}
+
+	details, err := v.gitService.GetCommitDetails(commit.Hash)
+	if err != nil {
+		details = errorStyle.Render(err.Error())
+	} else {
+		details = strings.Join(colorizeDiff(details), "\n")
+	}
+
+	v.detail.SetContent(details)
+	v.detail.GotoTop()
+	v.showDetail = true
+}
+
+func (v *CommitLogView) Update(msg tea.Msg) (*CommitLogView, tea.Cmd) {
+	if !v.visible {
+		return v, nil
+	}
+
+	// The wheel scrolls the open commit, or moves through the list
+	if mouseMsg, ok := msg.(tea.MouseMsg); ok {
+		if v.showDetail {
+			var cmd tea.Cmd
+			v.detail, cmd = v.detail.Update(mouseMsg)
+			return v, cmd
+		}
+		if mouseMsg.Action == tea.MouseActionPress {
+			switch mouseMsg.Button {
+			case tea.MouseButtonWheelUp:
+				v.moveCursor(-1)
+			case tea.MouseButtonWheelDown:
+				v.moveCursor(1)
+			}
+		}
+		return v, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return v, nil
+	}
+
+	if v.showDetail {
+		switch {
+		case key.Matches(keyMsg, v.keys.Back):
+			v.showDetail = false
+		case key.Matches(keyMsg, v.keys.Up):
+			v.detail.ScrollUp(1)
+		case key.Matches(keyMsg, v.keys.Down):
+			v.detail.ScrollDown(1)
+		case key.Matches(keyMsg, v.keys.PageUp):
+			v.detail.PageUp()
+		case key.Matches(keyMsg, v.keys.PageDown):
+			v.detail.PageDown()
+		}
+		return v, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, v.keys.Back):
+		v.Hide()
+	case key.Matches(keyMsg, v.keys.Up):
+		v.moveCursor(-1)
+	case key.Matches(keyMsg, v.keys.Down):
+		v.moveCursor(1)
+	case key.Matches(keyMsg, v.keys.PageUp):
+		v.moveCursor(-v.height)
+	case key.Matches(keyMsg, v.keys.PageDown):
+		v.moveCursor(v.height)
+	case key.Matches(keyMsg, v.keys.ToggleAll):
+		v.uniqueOnly = !v.uniqueOnly
+		v.reload()
+	case key.Matches(keyMsg, v.keys.Open):
+		v.openDetail()
+	case key.Matches(keyMsg, v.keys.Select):
+		if commit, ok := v.SelectedCommit(); ok {
+			v.selected[commit.FullHash] = !v.selected[commit.FullHash]
+		}
+	case key.Matches(keyMsg, v.keys.CherryPick):
+		if len(v.commits) > 0 {
+			v.action = CommitLogActionCherryPick
+		}
+	}
+
+	return v, nil
+}
+
+func (v *CommitLogView) View() string {
+	if !v.visible {
+		return ""
+	}
+
+	scope := "unique to branch"
+	if !v.uniqueOnly {
+		scope = "all history"
+	}
+
+	if v.showDetail {
+		commit, _ := v.SelectedCommit()
+		title := titleStyle.Render(fmt.Sprintf("Commit %s - %s", commit.Hash, v.branch))
+		help := helpStyle.Render(shortHelp(v.helpKeys(mainKeys.Help).short...))
+		return lipgloss.JoinVertical(lipgloss.Left, title, "", v.detail.View(), "", help)
+	}
+
+	title := titleStyle.Render(fmt.Sprintf("Commit Log - %s (%s)", v.branch, scope))
+
+	var lines []string
+	switch {
+	case v.err != nil:
+		lines = append(lines, errorStyle.Render(fmt.Sprintf("Error: %v", v.err)))
+	case len(v.commits) == 0:
+		lines = append(lines, timestampStyle.Render(fmt.Sprintf("No commits found (press %s to toggle all history)", v.keys.ToggleAll.Help().Key)))
+	}
+
+	end := min(v.offset+v.height, len(v.commits))
+	for i := v.offset; i < end; i++ {
+		commit := v.commits[i]
+		mark := " "
+		if v.selected[commit.FullHash] {
+			mark = "✓"
+		}
+		line := fmt.Sprintf("%s %s %s %s - %s",
+			mark,
+			commitHashStyle.Render(commit.Hash),
+			commitTimeStyle.Render(fmt.Sprintf("%-12s", commit.RelativeTime)),
+			commitAuthorStyle.Render(commit.Author),
+			truncateString(commit.Subject, 70))
+		if i == v.cursor {
+			line = commitLogSelectedStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+
+	for len(lines) < v.height {
+		lines = append(lines, "")
+	}
+
+	more := ""
+	if !v.exhausted {
+		more = "+"
+	}
+	status := timestampStyle.Render(fmt.Sprintf("%d/%d%s commits", min(v.cursor+1, len(v.commits)), len(v.commits), more))
+	help := helpStyle.Render(shortHelp(v.helpKeys(mainKeys.Help).short...))
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		commitContainerStyle.Height(v.height).Render(strings.Join(lines, "\n")),
+		status,
+		help,
+	)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Config is the user configuration, read from config.json in the user config directory
+// (e.g. ~/.config/recent-branches/config.json)
+type Config struct {
+	Columns       []string            `json:"columns"`        // Branch table columns, in display order
+	TicketPattern string              `json:"ticket_pattern"` // Regular expression that finds a ticket ID in a branch name
+	Theme         string              `json:"theme"`          // auto, dark, light, high-contrast, mono or a name from Themes
+	Themes        map[string]Theme    `json:"themes"`         // User-defined themes
+	Keymap        string              `json:"keymap"`         // Key binding preset: default or vim
+	Keys          map[string][]string `json:"keys"`           // Keys per binding name, overriding the preset
+	LogScrollback int                 `json:"log_scrollback"` // Entries kept in the log pane
+	CommitPolicy  CommitPolicy        `json:"commit_policy"`  // Rules for messages written in the commit modal
+}
+
+// DefaultConfig is used for anything the config file leaves out
+var DefaultConfig = Config{
+	Columns:       []string{"branch", "last-used", "commit-date", "title"},
+	TicketPattern: `[A-Z][A-Z0-9]+-[0-9]+`,
+	LogScrollback: 500,
+}
+
+// defaultConfigPath returns where the config file lives when no -config flag is given
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "recent-branches", "config.json")
+}
+
+// LoadConfig reads the config file at path, falling back to defaults for missing settings.
+// A missing file is not an error.
+func LoadConfig(path string) (Config, error) {
+	config := Config{}
+	if path != "" {
+		if err := loadJSONFile(path, &config); err != nil {
+			return DefaultConfig, err
+		}
+	}
+
+	if len(config.Columns) == 0 {
+		config.Columns = DefaultConfig.Columns
+	}
+	if config.TicketPattern == "" {
+		config.TicketPattern = DefaultConfig.TicketPattern
+	}
+	if config.LogScrollback <= 0 {
+		config.LogScrollback = DefaultConfig.LogScrollback
+	}
+
+	if _, err := resolveColumns(config); err != nil {
+		return DefaultConfig, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+	if _, err := resolveCommitPolicy(config); err != nil {
+		return DefaultConfig, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+	return config, nil
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"os/exec"
-	"sort"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -12,17 +14,29 @@ type Branch struct {
 	Name         string
 	CommitDate   time.Time
 	CommitTitle  string
+	Author       string    // Author of the latest commit
 	LastUsed     time.Time // When this branch was last checked out
+	Frecency     float64   // Checkouts weighted by how recent they were
+	AheadCount   int       // Commits on top of the base branch, only filled in when sorted or shown
+	BehindCount  int       // Commits on the base branch missing from this one
+	Upstream     string    // Upstream branch, filled in by the upstream column
+	Track        string    // Upstream ahead/behind summary, e.g. "ahead 1, behind 2" or "gone"
+	PullRequest  int       // Open pull request number, filled in by the PR column
+	Description  string    // First line of branch.<name>.description
 	IsRemote     bool
 	RelativeTime string
+	UpdatedBy    []string          // Authors of commits that arrived in the last background fetch
+	Prediction   *SwitchPrediction // Conflicts expected when switching to or merging this branch
 }
 
 type Commit struct {
 	Hash         string
+	FullHash     string
 	Subject      string
 	Author       string
 	Date         time.Time
 	RelativeTime string
+	Signature    string // Signature check from %G?: G good, N unsigned, others unverified
 }
 
 type GitService struct{}
@@ -32,10 +46,10 @@ func NewGitService() *GitService {
 }
 
 func (g *GitService) IsInRepository() error {
-	return exec.Command("git", "rev-parse", "--git-dir").Run()
+	return gitCommand("rev-parse", "--git-dir").Run()
 }
 
-func (g *GitService) GetRecentBranches(count int, includeRemote bool, authors []string) ([]Branch, error) {
+func (g *GitService) GetRecentBranches(count int, includeRemote bool, authors []string, order SortOrder) ([]Branch, error) {
 	if err := g.IsInRepository(); err != nil {
 		return nil, fmt.Errorf("not in a git repository")
 	}
@@ -51,14 +65,16 @@ func (g *GitService) GetRecentBranches(count int, includeRemote bool, authors []
 	}
 
 	// Get reflog information to find when branches were last used
-	reflogCmd := exec.Command("git", "reflog", "--all", "--grep-reflog=checkout:", "--format=%gd|%gs|%gt")
+	reflogCmd := gitCommand("reflog", "--all", "--grep-reflog=checkout:", "--date=unix", "--format=%gd|%gs")
 	reflogOutput, err := reflogCmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get git reflog: %v", err)
 	}
 
-	// Parse reflog to get last checkout times
+	// Parse reflog to get last checkout times and how often each branch is checked out
 	branchLastUsed := make(map[string]time.Time)
+	branchFrecency := make(map[string]float64)
+	now := time.Now()
 	reflogLines := strings.Split(strings.TrimSpace(string(reflogOutput)), "\n")
 
 	for _, line := range reflogLines {
@@ -66,13 +82,14 @@ func (g *GitService) GetRecentBranches(count int, includeRemote bool, authors []
 			continue
 		}
 
-		parts := strings.SplitN(line, "|", 3)
-		if len(parts) != 3 {
+		parts := strings.SplitN(line, "|", 2)
+		if len(parts) != 2 {
 			continue
 		}
 
 		subject := parts[1]
-		timestampStr := parts[2]
+		// With --date=unix the selector carries the timestamp: HEAD@{1700000000}
+		timestampStr := strings.TrimSuffix(parts[0][strings.LastIndex(parts[0], "{")+1:], "}")
 
 		// Parse checkout operations: "checkout: moving from branch1 to branch2"
 		if strings.Contains(subject, "checkout: moving from") {
@@ -86,6 +103,8 @@ func (g *GitService) GetRecentBranches(count int, includeRemote bool, authors []
 					continue
 				}
 
+				branchFrecency[targetBranch] += frecencyWeight(now.Sub(timestamp))
+
 				// Only record if this is the most recent checkout for this branch
 				if existing, exists := branchLastUsed[targetBranch]; !exists || timestamp.After(existing) {
 					branchLastUsed[targetBranch] = timestamp
@@ -148,13 +167,16 @@ func (g *GitService) GetRecentBranches(count int, includeRemote bool, authors []
 			// If no reflog entry, use commit date as fallback
 			branch.LastUsed = branch.CommitDate
 		}
+		branch.Frecency = branchFrecency[branchKey]
 		branches = append(branches, branch)
 	}
 
-	// Sort by last used time (most recent first)
-	sort.Slice(branches, func(i, j int) bool {
-		return branches[i].LastUsed.After(branches[j].LastUsed)
-	})
+	// Ahead counts cost a few git calls per branch, so only work them out when needed
+	if order.Key == SortAhead {
+		g.fillAheadBehind(branches)
+	}
+
+	sortBranches(branches, order)
 
 	// Limit to requested count
 	if len(branches) > count {
@@ -165,9 +187,9 @@ func (g *GitService) GetRecentBranches(count int, includeRemote bool, authors []
 }
 
 func (g *GitService) getBranchInfo(refPath string) ([]Branch, error) {
-	cmd := exec.Command("git", "for-each-ref",
+	cmd := gitCommand("for-each-ref",
 		"--sort=-committerdate",
-		"--format=%(refname:short)|%(committerdate:iso8601)|%(contents:subject)",
+		"--format=%(refname:short)|%(committerdate:iso8601)|%(authorname)|%(contents:subject)",
 		refPath)
 
 	output, err := cmd.Output()
@@ -187,14 +209,15 @@ func (g *GitService) getBranchInfo(refPath string) ([]Branch, error) {
 			continue
 		}
 
-		parts := strings.SplitN(line, "|", 3)
-		if len(parts) != 3 {
+		parts := strings.SplitN(line, "|", 4)
+		if len(parts) != 4 {
 			continue
 		}
 
 		branchName := strings.TrimSpace(parts[0])
 		dateStr := strings.TrimSpace(parts[1])
-		commitTitle := strings.TrimSpace(parts[2])
+		author := strings.TrimSpace(parts[2])
+		commitTitle := strings.TrimSpace(parts[3])
 
 		if branchName == "" {
 			continue
@@ -216,6 +239,7 @@ func (g *GitService) getBranchInfo(refPath string) ([]Branch, error) {
 			Name:        displayName,
 			CommitDate:  commitDate,
 			CommitTitle: commitTitle,
+			Author:      author,
 			IsRemote:    isRemote,
 		}
 
@@ -226,7 +250,7 @@ func (g *GitService) getBranchInfo(refPath string) ([]Branch, error) {
 }
 
 func (g *GitService) GetCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd := gitCommand("rev-parse", "--abbrev-ref", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -236,11 +260,11 @@ func (g *GitService) GetCurrentBranch() (string, error) {
 
 func (g *GitService) HasUncommittedChanges() (bool, error) {
 	// Check for staged changes
-	stagedCmd := exec.Command("git", "diff", "--cached", "--quiet")
+	stagedCmd := gitCommand("diff", "--cached", "--quiet")
 	stagedErr := stagedCmd.Run()
 
 	// Check for unstaged changes
-	unstagedCmd := exec.Command("git", "diff", "--quiet")
+	unstagedCmd := gitCommand("diff", "--quiet")
 	unstagedErr := unstagedCmd.Run()
 
 	// If either command returns non-zero, there are changes
@@ -261,7 +285,7 @@ type GitFileStatus struct {
 
 // GetGitStatus returns detailed git status information
 func (g *GitService) GetGitStatus() ([]GitFileStatus, error) {
-	cmd := exec.Command("git", "status", "--porcelain=v1")
+	cmd := gitCommand("status", "--porcelain=v1")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get git status: %v", err)
@@ -336,11 +360,11 @@ func (g *GitService) getFileLineStats(filePath, stagedStatus, workStatus string)
 
 // getNumstatForFile gets numstat for a specific file (staged or unstaged)
 func (g *GitService) getNumstatForFile(filePath string, staged bool) (int, int) {
-	var cmd *exec.Cmd
+	var cmd *tracedCmd
 	if staged {
-		cmd = exec.Command("git", "diff", "--numstat", "--cached", filePath)
+		cmd = gitCommand("diff", "--numstat", "--cached", filePath)
 	} else {
-		cmd = exec.Command("git", "diff", "--numstat", filePath)
+		cmd = gitCommand("diff", "--numstat", filePath)
 	}
 
 	output, err := cmd.Output()
@@ -400,10 +424,10 @@ func parseInt(s string) int {
 // GetFileDiff returns the diff for a specific file
 func (g *GitService) GetFileDiff(filePath string) (string, error) {
 	// Get both staged and unstaged changes
-	stagedCmd := exec.Command("git", "diff", "--cached", filePath)
+	stagedCmd := gitCommand("diff", "--cached", filePath)
 	stagedOutput, _ := stagedCmd.Output()
 
-	unstagedCmd := exec.Command("git", "diff", filePath)
+	unstagedCmd := gitCommand("diff", filePath)
 	unstagedOutput, _ := unstagedCmd.Output()
 
 	diff := ""
@@ -421,9 +445,19 @@ func (g *GitService) GetFileDiff(filePath string) (string, error) {
 	return diff, nil
 }
 
-func (g *GitService) CommitChanges(subject, description string) error {
+// CommitOptions changes how CommitChanges commits
+type CommitOptions struct {
+	Amend    bool   // Rewrite the last commit with the changes and message instead of adding one
+	FixupOf  string // Make a fixup! commit for this commit, ignoring the message
+	NoVerify bool   // Skip the pre-commit and commit-msg hooks
+	Sign     bool   // Sign with the configured key, whatever commit.gpgsign says
+}
+
+// CommitChanges stages everything and commits it, passing each line of output from git and
+// its hooks to output as it arrives
+func (g *GitService) CommitChanges(subject, description string, options CommitOptions, output func(string)) error {
 	// Stage all changes first
-	stageCmd := exec.Command("git", "add", "-A")
+	stageCmd := gitCommand("add", "-A")
 	if err := stageCmd.Run(); err != nil {
 		return fmt.Errorf("failed to stage changes: %v", err)
 	}
@@ -437,19 +471,38 @@ func (g *GitService) CommitChanges(subject, description string) error {
 	}
 
 	// Commit changes
-	commitCmd := exec.Command("git", "commit", "-m", message)
-	if err := commitCmd.Run(); err != nil {
-		return fmt.Errorf("failed to commit changes: %v", err)
+	args := []string{"commit"}
+	switch {
+	case options.FixupOf != "":
+		args = append(args, "--fixup="+options.FixupOf)
+	case options.Amend:
+		args = append(args, "--amend", "-m", message)
+	default:
+		args = append(args, "-m", message)
+	}
+	if options.NoVerify {
+		args = append(args, "--no-verify")
 	}
+	args = append(args, signingFlag(options.Sign))
+	return g.runWithProgress(output, args...)
+}
 
-	return nil
+// GetLastCommitMessage returns the subject and body of the commit at HEAD
+func (g *GitService) GetLastCommitMessage() (string, string, error) {
+	cmd := gitCommand("log", "-1", "--format=%B")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the last commit message: %v", err)
+	}
+	subject, body, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return subject, strings.TrimSpace(body), nil
 }
 
 func (g *GitService) StashChanges(branchName string) error {
 	// Create a descriptive stash message
 	stashMessage := fmt.Sprintf("WIP: changes before switching to %s", branchName)
 
-	stashCmd := exec.Command("git", "stash", "push", "-m", stashMessage)
+	stashCmd := gitCommand("stash", "push", "-m", stashMessage)
 	if err := stashCmd.Run(); err != nil {
 		return fmt.Errorf("failed to stash changes: %v", err)
 	}
@@ -457,7 +510,20 @@ func (g *GitService) StashChanges(branchName string) error {
 	return nil
 }
 
+// SwitchToBranch checks out a branch and records the switch in the navigation history
 func (g *GitService) SwitchToBranch(branchName string) error {
+	from, _ := g.GetCurrentBranch()
+	if err := g.checkoutBranch(branchName); err != nil {
+		return err
+	}
+
+	// History is a convenience; failing to record it shouldn't fail the switch
+	g.recordNavigation(from, strings.TrimSuffix(branchName, " (remote)"))
+	return nil
+}
+
+// checkoutBranch checks out a local branch, creating a tracking branch for remote ones
+func (g *GitService) checkoutBranch(branchName string) error {
 	// Remove remote indicator for display
 	actualBranchName := branchName
 	isRemote := strings.HasSuffix(branchName, " (remote)")
@@ -467,10 +533,10 @@ func (g *GitService) SwitchToBranch(branchName string) error {
 		actualBranchName = strings.TrimSuffix(branchName, " (remote)")
 
 		// Check if local branch exists
-		checkCmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+actualBranchName)
+		checkCmd := gitCommand("show-ref", "--verify", "--quiet", "refs/heads/"+actualBranchName)
 		if checkCmd.Run() != nil {
 			// Local branch doesn't exist, create and track it
-			createCmd := exec.Command("git", "checkout", "-b", actualBranchName, "origin/"+actualBranchName)
+			createCmd := gitCommand("checkout", "-b", actualBranchName, "origin/"+actualBranchName)
 			output, err := createCmd.CombinedOutput()
 			if err != nil {
 				return fmt.Errorf("failed to create and checkout branch %s: %v\nOutput: %s", actualBranchName, err, string(output))
@@ -480,7 +546,7 @@ func (g *GitService) SwitchToBranch(branchName string) error {
 	}
 
 	// Switch to existing local branch
-	cmd := exec.Command("git", "checkout", actualBranchName)
+	cmd := gitCommand("checkout", actualBranchName)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to checkout branch %s: %v\nOutput: %s", actualBranchName, err, string(output))
@@ -490,11 +556,11 @@ func (g *GitService) SwitchToBranch(branchName string) error {
 }
 
 func (g *GitService) GetCurrentUser() (string, error) {
-	cmd := exec.Command("git", "config", "user.email")
+	cmd := gitCommand("config", "user.email")
 	output, err := cmd.Output()
 	if err != nil {
 		// Fallback to user.name if email not available
-		cmd = exec.Command("git", "config", "user.name")
+		cmd = gitCommand("config", "user.name")
 		output, err = cmd.Output()
 		if err != nil {
 			return "", err
@@ -528,7 +594,7 @@ func (g *GitService) branchHasAuthorCommits(branch Branch, authors []string, cur
 	}
 
 	// Get commits that are in this branch but not in the base branch
-	cmd := exec.Command("git", "log", "--format=%ae|%an", mergeBase+".."+gitBranchName)
+	cmd := gitCommand("log", "--format=%ae|%an", mergeBase+".."+gitBranchName)
 	output, err := cmd.Output()
 	if err != nil {
 		// If we can't get commits, include the branch
@@ -576,25 +642,84 @@ func (g *GitService) branchHasAuthorCommits(branch Branch, authors []string, cur
 }
 
 func (g *GitService) findMergeBase(branchName string) (string, error) {
+	_, mergeBase, err := g.findBaseBranch(branchName)
+	return mergeBase, err
+}
+
+// findBaseBranch returns the base branch a branch was forked from along with their merge base
+func (g *GitService) findBaseBranch(branchName string) (string, string, error) {
 	// Try common base branches
 	baseBranches := []string{"main", "master", "develop", "dev"}
 
 	for _, base := range baseBranches {
-		cmd := exec.Command("git", "merge-base", base, branchName)
+		cmd := gitCommand("merge-base", base, branchName)
 		output, err := cmd.Output()
 		if err == nil && strings.TrimSpace(string(output)) != "" {
-			return strings.TrimSpace(string(output)), nil
+			return base, strings.TrimSpace(string(output)), nil
 		}
 	}
 
 	// Fallback: use the first commit in the repository
-	cmd := exec.Command("git", "rev-list", "--max-parents=0", "HEAD")
+	cmd := gitCommand("rev-list", "--max-parents=0", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	return strings.TrimSpace(string(output)), nil
+	// A repository can have several root commits; use the first one
+	root := strings.Fields(string(output))
+	if len(root) == 0 {
+		return "", "", fmt.Errorf("no root commit found")
+	}
+
+	return "root commit", root[0], nil
+}
+
+// gitRefForBranch converts a display name such as "feature (remote)" into a ref git understands
+func gitRefForBranch(branchName string) string {
+	if strings.HasSuffix(branchName, " (remote)") {
+		return "origin/" + strings.TrimSuffix(branchName, " (remote)")
+	}
+	return branchName
+}
+
+// BranchDiff describes what a branch changes relative to its merge base with the base branch
+type BranchDiff struct {
+	Branch    string
+	Base      string
+	MergeBase string
+	Stat      string
+	Patch     string
+}
+
+// GetBranchDiff returns the diffstat and unified diff of a branch against its merge base
+func (g *GitService) GetBranchDiff(branchName string) (*BranchDiff, error) {
+	gitBranchName := gitRefForBranch(branchName)
+
+	base, mergeBase, err := g.findBaseBranch(gitBranchName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find merge base for %s: %v", branchName, err)
+	}
+
+	statCmd := gitCommand("diff", "--stat", mergeBase, gitBranchName)
+	statOutput, err := statCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diffstat for %s: %v", branchName, err)
+	}
+
+	patchCmd := gitCommand("diff", mergeBase, gitBranchName)
+	patchOutput, err := patchCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff for %s: %v", branchName, err)
+	}
+
+	return &BranchDiff{
+		Branch:    branchName,
+		Base:      base,
+		MergeBase: mergeBase,
+		Stat:      strings.TrimRight(string(statOutput), "\n"),
+		Patch:     strings.TrimRight(string(patchOutput), "\n"),
+	}, nil
 }
 
 func parseGitDate(dateStr string) (time.Time, error) {
@@ -617,7 +742,11 @@ func parseGitDate(dateStr string) (time.Time, error) {
 
 func parseTimestamp(timestampStr string) (time.Time, error) {
 	// Git reflog timestamps are Unix timestamps
-	return time.Parse("1136239445", timestampStr)
+	seconds, err := strconv.ParseInt(strings.TrimSpace(timestampStr), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, 0), nil
 }
 
 // GetBranchCommits returns recent commits for a specific branch
@@ -639,9 +768,9 @@ func (g *GitService) GetBranchCommits(branchName string, count int) ([]Commit, e
 	}
 
 	// Get commits for the branch
-	cmd := exec.Command("git", "log",
+	cmd := gitCommand("log",
 		fmt.Sprintf("-%d", count),
-		"--format=%H|%s|%an|%ci",
+		"--format=%H|%G?|%s|%an|%ci",
 		gitBranchName)
 
 	output, err := cmd.Output()
@@ -649,11 +778,16 @@ func (g *GitService) GetBranchCommits(branchName string, count int) ([]Commit, e
 		return nil, fmt.Errorf("failed to get commits for branch %s: %v", branchName, err)
 	}
 
-	if strings.TrimSpace(string(output)) == "" {
-		return []Commit{}, nil
+	return parseCommitLog(string(output)), nil
+}
+
+// parseCommitLog parses git log output in the "%H|%G?|%s|%an|%ci" format
+func parseCommitLog(output string) []Commit {
+	if strings.TrimSpace(output) == "" {
+		return []Commit{}
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	lines := strings.Split(strings.TrimSpace(output), "\n")
 	var commits []Commit
 
 	for _, line := range lines {
@@ -661,15 +795,16 @@ func (g *GitService) GetBranchCommits(branchName string, count int) ([]Commit, e
 			continue
 		}
 
-		parts := strings.SplitN(line, "|", 4)
-		if len(parts) != 4 {
+		parts := strings.SplitN(line, "|", 5)
+		if len(parts) != 5 {
 			continue
 		}
 
 		hash := strings.TrimSpace(parts[0])
-		subject := strings.TrimSpace(parts[1])
-		author := strings.TrimSpace(parts[2])
-		dateStr := strings.TrimSpace(parts[3])
+		signature := strings.TrimSpace(parts[1])
+		subject := strings.TrimSpace(parts[2])
+		author := strings.TrimSpace(parts[3])
+		dateStr := strings.TrimSpace(parts[4])
 
 		// Parse the commit date
 		commitDate, err := parseGitDate(dateStr)
@@ -679,14 +814,345 @@ func (g *GitService) GetBranchCommits(branchName string, count int) ([]Commit, e
 
 		commit := Commit{
 			Hash:         hash[:8], // Short hash
+			FullHash:     hash,
 			Subject:      subject,
 			Author:       author,
 			Date:         commitDate,
+			Signature:    signature,
 			RelativeTime: formatLastUsedTime(commitDate),
 		}
 
 		commits = append(commits, commit)
 	}
 
-	return commits, nil
+	return commits
+}
+
+// GetBranchLog returns a page of a branch's history, optionally limited to commits not on its base branch
+func (g *GitService) GetBranchLog(branchName string, skip, count int, uniqueOnly bool) ([]Commit, error) {
+	gitBranchName := gitRefForBranch(branchName)
+
+	revRange := gitBranchName
+	if uniqueOnly {
+		_, mergeBase, err := g.findBaseBranch(gitBranchName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find merge base for %s: %v", branchName, err)
+		}
+		revRange = mergeBase + ".." + gitBranchName
+	}
+
+	cmd := gitCommand("log",
+		fmt.Sprintf("--skip=%d", skip),
+		fmt.Sprintf("-%d", count),
+		"--format=%H|%G?|%s|%an|%ci",
+		revRange)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log for branch %s: %v", branchName, err)
+	}
+
+	return parseCommitLog(string(output)), nil
+}
+
+// GetCommitDetails returns the full message, stat and patch of a commit
+func (g *GitService) GetCommitDetails(hash string) (string, error) {
+	cmd := gitCommand("show", "--format=fuller", "--stat", "--patch", hash)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to show commit %s: %v", hash, err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// GetOperationInProgress reports which multi-step git operation, if any, is waiting on the user
+func (g *GitService) GetOperationInProgress() GitOperation {
+	if g.gitPathExists("rebase-merge") || g.gitPathExists("rebase-apply") {
+		return OperationRebase
+	}
+	cmd := gitCommand("rev-parse", "-q", "--verify", "CHERRY_PICK_HEAD")
+	if cmd.Run() == nil {
+		return OperationCherryPick
+	}
+	return OperationNone
+}
+
+// gitPathExists checks whether a file or directory exists inside the git directory
+func (g *GitService) gitPathExists(name string) bool {
+	cmd := gitCommand("rev-parse", "--git-path", name)
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(strings.TrimSpace(string(output)))
+	return err == nil
+}
+
+// GetConflictedFiles returns the paths that still have unresolved conflicts
+func (g *GitService) GetConflictedFiles() ([]string, error) {
+	cmd := gitCommand("diff", "--name-only", "--diff-filter=U")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicted files: %v", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// conflictOrError turns a failed operation into a ConflictError if git stopped on conflicts
+func (g *GitService) conflictOrError(op GitOperation, err error, output []byte) error {
+	return g.operationError(op, fmt.Errorf("%s failed: %v\nOutput: %s", op, err, strings.TrimSpace(string(output))))
+}
+
+// operationError is conflictOrError for commands run with runWithProgress, whose errors
+// already carry the output
+func (g *GitService) operationError(op GitOperation, err error) error {
+	if err == nil {
+		return nil
+	}
+	if g.GetOperationInProgress() == op {
+		files, _ := g.GetConflictedFiles()
+		return &ConflictError{Operation: op, Files: files}
+	}
+	return err
+}
+
+// CherryPick applies the given commits onto HEAD in the order provided
+func (g *GitService) CherryPick(hashes []string) error {
+	if len(hashes) == 0 {
+		return fmt.Errorf("no commits to cherry-pick")
+	}
+
+	args := append([]string{"cherry-pick"}, hashes...)
+	cmd := gitCommand(args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return g.conflictOrError(OperationCherryPick, err, output)
+	}
+
+	return nil
+}
+
+// ContinueOperation resumes a stopped operation after conflicts have been resolved
+func (g *GitService) ContinueOperation(op GitOperation) error {
+	cmd := gitCommand(op.command(), "--continue")
+	// Keep the prepared commit message instead of opening an editor
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return g.conflictOrError(op, err, output)
+	}
+	return nil
+}
+
+// SkipOperation drops the commit that stopped the operation and carries on with the rest
+func (g *GitService) SkipOperation(op GitOperation) error {
+	cmd := gitCommand(op.command(), "--skip")
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return g.conflictOrError(op, err, output)
+	}
+	return nil
+}
+
+// AbortOperation cancels a stopped operation and restores the previous state
+func (g *GitService) AbortOperation(op GitOperation) error {
+	cmd := gitCommand(op.command(), "--abort")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to abort %s: %v\nOutput: %s", op, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// rebaseBackupRef is where the tip of a branch is recorded before the tool rebases it
+func rebaseBackupRef(branchName string) string {
+	return "refs/recent-branches/pre-rebase/" + branchName
+}
+
+// RebaseBranch rebases a local branch onto another ref, passing each line of git's output to
+// progress and recording the previous tip for undo. A branch checked out in another worktree
+// is rebased there. Other branches are rebased in a temporary worktree so the working tree is
+// left alone; if that stops on conflicts the rebase is redone here to resolve them.
+func (g *GitService) RebaseBranch(branchName, onto string, progress func(string)) error {
+	tipCmd := gitCommand("rev-parse", "--verify", "refs/heads/"+branchName)
+	tipOutput, err := tipCmd.Output()
+	if err != nil {
+		return fmt.Errorf("branch %s is not a local branch", branchName)
+	}
+	tip := strings.TrimSpace(string(tipOutput))
+
+	backupCmd := gitCommand("update-ref", "-m", "recent-branches: pre-rebase tip", rebaseBackupRef(branchName), tip)
+	if output, err := backupCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to record pre-rebase tip: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	if currentBranch, _ := g.GetCurrentBranch(); currentBranch == branchName {
+		return g.operationError(OperationRebase, g.runWithProgress(progress, "rebase", onto))
+	}
+
+	worktree, err := g.branchWorktree(branchName)
+	if err != nil {
+		return err
+	}
+	if worktree != "" {
+		progress(fmt.Sprintf("%s is checked out in %s, rebasing there", branchName, worktree))
+		if err := g.runWithProgress(progress, "-C", worktree, "rebase", onto); err != nil {
+			return fmt.Errorf("%v\nResolve or abort the rebase in %s", err, worktree)
+		}
+		return nil
+	}
+
+	// Only conflicts need the branch checked out here, anything else is reported as is
+	err = g.rebaseInWorktree(branchName, onto, progress)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		return err
+	}
+
+	// Rebase in the main worktree, checking the branch out, so conflicts can be resolved
+	if dirty, _ := g.HasUncommittedChanges(); dirty {
+		return fmt.Errorf("rebase of %s stopped on conflicts in %s; commit or stash your changes to resolve them here",
+			branchName, strings.Join(conflict.Files, ", "))
+	}
+	progress(fmt.Sprintf("Checking out %s to resolve the conflicts", branchName))
+	return g.operationError(OperationRebase, g.runWithProgress(progress, "rebase", onto, branchName))
+}
+
+// branchWorktree returns the worktree a branch is checked out in, or "" if it isn't checked out
+func (g *GitService) branchWorktree(branchName string) (string, error) {
+	cmd := gitCommand("worktree", "list", "--porcelain")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list worktrees: %v", err)
+	}
+
+	var worktree string
+	for _, line := range strings.Split(string(output), "\n") {
+		if path, ok := strings.CutPrefix(line, "worktree "); ok {
+			worktree = path
+		} else if line == "branch refs/heads/"+branchName {
+			return worktree, nil
+		}
+	}
+	return "", nil
+}
+
+// AutosquashFixups squashes the fixup! commits on the current branch into their targets,
+// rebasing from target, the oldest commit they fix up. Merges in the range are kept. The
+// rewritten commits are signed if sign is set, otherwise as commit.gpgsign says. The
+// previous tip is recorded for undo.
+func (g *GitService) AutosquashFixups(target string, sign bool, progress func(string)) error {
+	branchName, err := g.GetCurrentBranch()
+	if err != nil || branchName == "HEAD" {
+		return fmt.Errorf("can't squash fixups without a current branch")
+	}
+
+	backupCmd := gitCommand("update-ref", "-m", "recent-branches: pre-rebase tip", rebaseBackupRef(branchName), "HEAD")
+	if output, err := backupCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to record pre-rebase tip: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	// Rebase from the target's parent, or from the root when the target has none
+	upstream := []string{"--root"}
+	if gitCommand("rev-parse", "-q", "--verify", target+"^").Run() == nil {
+		upstream = []string{target + "^"}
+	}
+
+	// --autosquash only works with --interactive; accept the todo list it prepares as is
+	args := []string{"rebase", "--interactive", "--autosquash", "--rebase-merges"}
+	// Turning signing off is left to commit.gpgsign, so signatures in the range aren't dropped
+	if sign {
+		args = append(args, signingFlag(sign))
+	}
+	args = append(args, upstream...)
+	cmd := gitCommand(args...)
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=true", "GIT_EDITOR=true")
+	return g.operationError(OperationRebase, g.streamCommand(cmd, progress))
+}
+
+// rebaseInWorktree rebases a branch in a throwaway worktree, leaving the main one alone. A
+// rebase that stops on conflicts is aborted and returned as a ConflictError.
+func (g *GitService) rebaseInWorktree(branchName, onto string, progress func(string)) error {
+	dir, err := os.MkdirTemp("", "recent-branches-rebase-")
+	if err != nil {
+		return fmt.Errorf("failed to create a worktree for the rebase: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	addCmd := gitCommand("worktree", "add", "--quiet", dir, branchName)
+	if output, err := addCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to check out %s in a worktree for the rebase: %v\nOutput: %s", branchName, err, strings.TrimSpace(string(output)))
+	}
+	defer gitCommand("worktree", "remove", "--force", dir).Run()
+
+	if err := g.runWithProgress(progress, "-C", dir, "rebase", onto); err != nil {
+		files, stopped := g.rebaseStoppedIn(dir)
+		gitCommand("-C", dir, "rebase", "--abort").Run()
+		if stopped {
+			return &ConflictError{Operation: OperationRebase, Files: files}
+		}
+		return err
+	}
+	return nil
+}
+
+// rebaseStoppedIn reports whether a rebase is waiting in a worktree, with its conflicted files
+func (g *GitService) rebaseStoppedIn(dir string) ([]string, bool) {
+	for _, name := range []string{"rebase-merge", "rebase-apply"} {
+		output, err := gitCommand("-C", dir, "rev-parse", "--git-path", name).Output()
+		if err != nil {
+			continue
+		}
+		path := strings.TrimSpace(string(output))
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			output, _ := gitCommand("-C", dir, "diff", "--name-only", "--diff-filter=U").Output()
+			return strings.Fields(string(output)), true
+		}
+	}
+	return nil, false
+}
+
+// GetRebaseBackup returns the recorded pre-rebase tip of a branch, if there is one
+func (g *GitService) GetRebaseBackup(branchName string) (string, bool) {
+	cmd := gitCommand("rev-parse", "-q", "--verify", rebaseBackupRef(branchName))
+	output, err := cmd.Output()
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(output)), true
+}
+
+// UndoRebase moves a branch back to the tip recorded before it was rebased
+func (g *GitService) UndoRebase(branchName string) error {
+	tip, ok := g.GetRebaseBackup(branchName)
+	if !ok {
+		return fmt.Errorf("no recorded rebase to undo for %s", branchName)
+	}
+
+	var cmd *tracedCmd
+	if currentBranch, _ := g.GetCurrentBranch(); currentBranch == branchName {
+		// Keep local changes that don't conflict with the reset
+		cmd = gitCommand("reset", "--keep", tip)
+	} else {
+		cmd = gitCommand("update-ref", "-m", "recent-branches: undo rebase", "refs/heads/"+branchName, tip)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restore %s to %s: %v\nOutput: %s", branchName, tip[:8], err, strings.TrimSpace(string(output)))
+	}
+
+	return gitCommand("update-ref", "-d", rebaseBackupRef(branchName)).Run()
+}
+
+// CountCommitsBetween returns how many commits are reachable from head but not from base
+func (g *GitService) CountCommitsBetween(base, head string) (int, error) {
+	cmd := gitCommand("rev-list", "--count", base+".."+head)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits in %s..%s: %v", base, head, err)
+	}
+	return parseInt(strings.TrimSpace(string(output))), nil
 }
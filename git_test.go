@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRebaseBranchInOwningWorktree(t *testing.T) {
+	dir := newTestRepo(t)
+	gitRun(t, "branch", "feature")
+	commitFile(t, "base", "base\n", "Move main on")
+
+	worktree := filepath.Join(t.TempDir(), "feature")
+	gitRun(t, "worktree", "add", "-q", worktree, "feature")
+	t.Chdir(worktree)
+	commitFile(t, "feature", "feature\n", "Feature work")
+
+	// Local changes in the main worktree must not get in the way
+	t.Chdir(dir)
+	if err := os.WriteFile("README", []byte("dirty\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	err := NewGitService().RebaseBranch("feature", "main", func(line string) { lines = append(lines, line) })
+	if err != nil {
+		t.Fatalf("RebaseBranch: %v", err)
+	}
+	if len(lines) == 0 {
+		t.Error("expected rebase progress output")
+	}
+
+	if got := gitRun(t, "rev-parse", "feature~1"); got != gitRun(t, "rev-parse", "main") {
+		t.Errorf("feature is not on top of main")
+	}
+	if got := gitRun(t, "branch", "--show-current"); got != "main" {
+		t.Errorf("main worktree switched to %s", got)
+	}
+	if _, ok := NewGitService().GetRebaseBackup("feature"); !ok {
+		t.Error("pre-rebase tip was not recorded")
+	}
+}
+
+func TestRebaseBranchReportsWorktreeFailures(t *testing.T) {
+	newTestRepo(t)
+	gitRun(t, "branch", "feature")
+
+	err := NewGitService().RebaseBranch("feature", "missing", func(string) {})
+	if err == nil {
+		t.Fatal("expected an error rebasing onto a missing ref")
+	}
+	var conflict *ConflictError
+	if errors.As(err, &conflict) {
+		t.Errorf("got a conflict for a missing ref: %v", err)
+	}
+	if got := gitRun(t, "branch", "--show-current"); got != "main" {
+		t.Errorf("fell back to checking out %s", got)
+	}
+}
+
+func TestRebaseBranchConflictsNeedCleanTree(t *testing.T) {
+	newTestRepo(t)
+	gitRun(t, "checkout", "-q", "-b", "feature")
+	commitFile(t, "shared", "feature\n", "Feature change")
+	gitRun(t, "checkout", "-q", "main")
+	commitFile(t, "shared", "main\n", "Main change")
+	if err := os.WriteFile("README", []byte("dirty\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := NewGitService().RebaseBranch("feature", "main", func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "shared") {
+		t.Fatalf("expected the conflicted file in the error, got %v", err)
+	}
+	if got := gitRun(t, "branch", "--show-current"); got != "main" {
+		t.Errorf("switched to %s with a dirty tree", got)
+	}
+}
+
+func TestAutosquashFixupsKeepsMerges(t *testing.T) {
+	newTestRepo(t)
+	commitFile(t, "a.txt", "a\n", "Add a")
+	target := gitRun(t, "rev-parse", "HEAD")
+	gitRun(t, "checkout", "-q", "-b", "side")
+	commitFile(t, "side.txt", "side\n", "Add side")
+	gitRun(t, "checkout", "-q", "main")
+	gitRun(t, "merge", "-q", "--no-ff", "-m", "Merge side", "side")
+	commitFile(t, "a.txt", "a fixed\n", "fixup! Add a")
+
+	var lines []string
+	if err := NewGitService().AutosquashFixups(target, false, func(line string) { lines = append(lines, line) }); err != nil {
+		t.Fatal(err)
+	}
+
+	if subjects := gitRun(t, "log", "--format=%s"); strings.Contains(subjects, "fixup!") {
+		t.Errorf("fixup wasn't squashed:\n%s", subjects)
+	}
+	if merges := gitRun(t, "rev-list", "--count", "--merges", "HEAD"); merges != "1" {
+		t.Errorf("got %s merges after squashing, want 1", merges)
+	}
+	if content := gitRun(t, "show", "HEAD~1:a.txt"); content != "a fixed" {
+		t.Errorf("a.txt in the fixed up commit is %q", content)
+	}
+	if len(lines) == 0 {
+		t.Error("no progress was reported")
+	}
+}
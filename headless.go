@@ -0,0 +1,402 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Limits for headless script runs
+const (
+	scriptStepTimeout = 30 * time.Second // Longest a step may wait on the commands it started
+	scriptWidth       = 120              // Window size sent before the script runs
+	scriptHeight      = 40
+	scriptLogLines    = 20 // Most recent log entries included in the state
+)
+
+// scriptStep is one line of a headless script turned into the messages it sends
+type scriptStep struct {
+	line     int
+	msgs     []tea.Msg
+	snapshot bool   // Record the view and state instead of sending messages
+	name     string // Snapshot name
+}
+
+// scriptState is the part of the model a script can make assertions on
+type scriptState struct {
+	CurrentBranch  string   `json:"current_branch"`
+	SelectedBranch string   `json:"selected_branch"`
+	Cursor         int      `json:"cursor"`
+	Branches       []string `json:"branches"`
+	Tab            string   `json:"tab"`
+	Focus          string   `json:"focus"`
+	Message        string   `json:"message"`
+	Error          string   `json:"error,omitempty"`
+	Modal          bool     `json:"modal"`
+	CommitLog      bool     `json:"commit_log"`
+	Help           bool     `json:"help"`
+	Operation      string   `json:"operation"`
+	Quitting       bool     `json:"quitting"`
+	Width          int      `json:"width"`
+	Height         int      `json:"height"`
+	Logs           []string `json:"logs"`
+}
+
+type scriptSnapshot struct {
+	Name  string      `json:"name"`
+	Line  int         `json:"line"`
+	View  string      `json:"view"`
+	State scriptState `json:"state"`
+}
+
+// scriptResult is printed as JSON when a script finishes
+type scriptResult struct {
+	View      string           `json:"view"`
+	State     scriptState      `json:"state"`
+	Snapshots []scriptSnapshot `json:"snapshots"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// keyTypes maps key names as bubbletea prints them ("enter", "ctrl+c", "pgdown") to key types
+var keyTypes = func() map[string]tea.KeyType {
+	types := map[string]tea.KeyType{"space": tea.KeySpace}
+	for k := tea.KeyType(-200); k < 200; k++ {
+		if name := k.String(); name != "" && name != " " && k != tea.KeyRunes {
+			types[name] = k
+		}
+	}
+	return types
+}()
+
+// parseKey turns a key name such as "q", "enter", "ctrl+s" or "alt+b" into a key message
+func parseKey(name string) (tea.KeyMsg, error) {
+	if keyType, ok := keyTypes[name]; ok {
+		msg := tea.KeyMsg{Type: keyType}
+		if keyType == tea.KeySpace {
+			msg.Runes = []rune{' '}
+		}
+		return msg, nil
+	}
+
+	if rest, ok := strings.CutPrefix(name, "alt+"); ok && rest != "" {
+		msg, err := parseKey(rest)
+		if err != nil {
+			return msg, err
+		}
+		msg.Alt = true
+		return msg, nil
+	}
+	if utf8.RuneCountInString(name) != 1 {
+		return tea.KeyMsg{}, fmt.Errorf("unknown key %q", name)
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}, nil
+}
+
+// parseScript reads a headless script. Each line is one of:
+//
+//	size W H          resize the window
+//	key NAME...       press keys, e.g. "key down down enter" or "key ctrl+s"
+//	type TEXT         type the rest of the line one character at a time
+//	click X Y         left click at a cell
+//	wheel up|down X Y scroll the mouse wheel at a cell
+//	snapshot [NAME]   record the view and state at this point
+//
+// Blank lines and lines starting with # are ignored.
+func parseScript(r io.Reader) ([]scriptStep, error) {
+	var steps []scriptStep
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		command, rest, _ := strings.Cut(line, " ")
+		fields := strings.Fields(rest)
+		step := scriptStep{line: lineNo}
+		var err error
+
+		switch command {
+		case "size":
+			var size []int
+			if size, err = parseInts(fields, 2); err == nil {
+				step.msgs = append(step.msgs, tea.WindowSizeMsg{Width: size[0], Height: size[1]})
+			}
+		case "key":
+			if len(fields) == 0 {
+				err = fmt.Errorf("key needs at least one key name")
+			}
+			for _, name := range fields {
+				var msg tea.KeyMsg
+				if msg, err = parseKey(name); err != nil {
+					break
+				}
+				step.msgs = append(step.msgs, msg)
+			}
+		case "type":
+			for _, r := range rest {
+				if r == ' ' {
+					step.msgs = append(step.msgs, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{r}})
+				} else {
+					step.msgs = append(step.msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+				}
+			}
+		case "click":
+			var at []int
+			if at, err = parseInts(fields, 2); err == nil {
+				step.msgs = append(step.msgs, mouseMsg(tea.MouseButtonLeft, at[0], at[1]))
+			}
+		case "wheel":
+			button := tea.MouseButtonWheelDown
+			if len(fields) > 0 && fields[0] == "up" {
+				button = tea.MouseButtonWheelUp
+			} else if len(fields) == 0 || fields[0] != "down" {
+				err = fmt.Errorf("wheel needs up or down")
+				break
+			}
+			var at []int
+			if at, err = parseInts(fields[1:], 2); err == nil {
+				step.msgs = append(step.msgs, mouseMsg(button, at[0], at[1]))
+			}
+		case "snapshot":
+			step.snapshot = true
+			step.name = strings.TrimSpace(rest)
+		default:
+			err = fmt.Errorf("unknown command %q", command)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+		steps = append(steps, step)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read script: %v", err)
+	}
+	return steps, nil
+}
+
+func parseInts(fields []string, count int) ([]int, error) {
+	if len(fields) != count {
+		return nil, fmt.Errorf("expected %d numbers, got %d", count, len(fields))
+	}
+	values := make([]int, count)
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid number %q", field)
+		}
+		values[i] = n
+	}
+	return values, nil
+}
+
+func mouseMsg(button tea.MouseButton, x, y int) tea.MouseMsg {
+	return tea.MouseMsg{X: x, Y: y, Button: button, Action: tea.MouseActionPress}
+}
+
+// scriptDriver feeds messages into the model the way a tea.Program would, running the
+// commands Update returns until none are left
+type scriptDriver struct {
+	model model
+	quit  bool
+}
+
+// send delivers msg and every message the resulting commands produce
+func (d *scriptDriver) send(msg tea.Msg) error {
+	results := make(chan tea.Msg)
+	pending := 0
+	start := func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		pending++
+		go func() { results <- cmd() }()
+	}
+
+	deadline := time.After(scriptStepTimeout)
+	queue := []tea.Msg{msg}
+	for len(queue) > 0 || pending > 0 {
+		if len(queue) == 0 {
+			select {
+			case msg := <-results:
+				pending--
+				queue = append(queue, msg)
+			case <-deadline:
+				return fmt.Errorf("timed out after %v waiting for commands to finish", scriptStepTimeout)
+			}
+			continue
+		}
+
+		msg := queue[0]
+		queue = queue[1:]
+		switch msg := msg.(type) {
+		case nil:
+			continue
+		case tea.QuitMsg:
+			d.quit = true
+			return nil
+		case tea.BatchMsg:
+			for _, cmd := range msg {
+				start(cmd)
+			}
+			continue
+		}
+
+		updated, cmd := d.model.Update(msg)
+		d.model = updated.(model)
+		start(cmd)
+	}
+	return nil
+}
+
+// view is the rendered screen without colors or trailing spaces
+func (d *scriptDriver) view() string {
+	lines := strings.Split(ansi.Strip(d.model.View()), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (d *scriptDriver) state() scriptState {
+	m := &d.model
+	state := scriptState{
+		Cursor:    m.tableManager.GetCursor(),
+		Branches:  []string{},
+		Tab:       "branches",
+		Focus:     "table",
+		Message:   m.message,
+		Modal:     m.commitModal.IsVisible(),
+		CommitLog: m.commitLog.IsVisible(),
+		Help:      m.showHelp,
+		Operation: m.operation.String(),
+		Quitting:  m.quitting,
+		Width:     m.width,
+		Height:    m.height,
+		Logs:      []string{},
+	}
+
+	if current, err := m.gitService.GetCurrentBranch(); err == nil {
+		state.CurrentBranch = current
+	}
+	if branch, ok := m.selectedBranch(); ok {
+		state.SelectedBranch = branch.Name
+	}
+	for _, branch := range m.branches {
+		state.Branches = append(state.Branches, branch.Name)
+	}
+	if m.activeTab == TabTags {
+		state.Tab = "tags"
+	}
+	switch {
+	case m.logViewer.focused:
+		state.Focus = "logs"
+	case m.diffViewer.focused:
+		state.Focus = "diff"
+	case m.previewFocused:
+		state.Focus = "preview"
+	}
+	if m.err != nil {
+		state.Error = m.err.Error()
+	}
+
+	entries := m.logViewer.entries
+	if len(entries) > scriptLogLines {
+		entries = entries[len(entries)-scriptLogLines:]
+	}
+	for _, entry := range entries {
+		state.Logs = append(state.Logs, entry.Level.String()+" "+entry.Message)
+	}
+	return state
+}
+
+// runScript drives the model with a script instead of a terminal and prints the final view
+// and state as JSON. A path of "-" reads the script from stdin.
+func runScript(m model, path string) int {
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to open script: %v\n", err)
+			return 2
+		}
+		defer file.Close()
+		input = file
+	}
+
+	steps, err := parseScript(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	result := playScript(m, steps)
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if result.Error != "" {
+		return 1
+	}
+	return 0
+}
+
+// playScript runs the steps of a script against the model and collects the snapshots
+func playScript(m model, steps []scriptStep) scriptResult {
+	// Blinking cursors and background fetches would make runs depend on timing
+	m.fetchInterval = 0
+	m.commitModal.subject.Cursor.SetMode(cursor.CursorStatic)
+	m.commitModal.description.Cursor.SetMode(cursor.CursorStatic)
+	m.tagsView.branchInput.Cursor.SetMode(cursor.CursorStatic)
+	m.logViewer.search.Cursor.SetMode(cursor.CursorStatic)
+
+	d := &scriptDriver{model: m}
+	result := scriptResult{Snapshots: []scriptSnapshot{}}
+
+	err := d.send(tea.WindowSizeMsg{Width: scriptWidth, Height: scriptHeight})
+	if err == nil {
+		err = d.send(tea.BatchMsg{d.model.Init()})
+	}
+	for _, step := range steps {
+		if err != nil || d.quit {
+			break
+		}
+		if step.snapshot {
+			result.Snapshots = append(result.Snapshots, scriptSnapshot{
+				Name:  step.name,
+				Line:  step.line,
+				View:  d.view(),
+				State: d.state(),
+			})
+			continue
+		}
+		for _, msg := range step.msgs {
+			if err = d.send(msg); err != nil || d.quit {
+				break
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("line %d: %v", step.line, err)
+		}
+	}
+
+	result.View = d.view()
+	result.State = d.state()
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseScript(t *testing.T) {
+	steps, err := parseScript(strings.NewReader(`# Comment
+size 80 24
+
+key down ctrl+s alt+b
+type hi
+click 3 4
+wheel up 1 2
+snapshot after
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 6 {
+		t.Fatalf("got %d steps, want 6", len(steps))
+	}
+
+	if size, ok := steps[0].msgs[0].(tea.WindowSizeMsg); !ok || size.Width != 80 || size.Height != 24 || steps[0].line != 2 {
+		t.Errorf("size step: %+v", steps[0])
+	}
+	keys := steps[1].msgs
+	if len(keys) != 3 || keys[0].(tea.KeyMsg).Type != tea.KeyDown || keys[1].(tea.KeyMsg).Type != tea.KeyCtrlS {
+		t.Errorf("key step: %+v", keys)
+	}
+	if alt := keys[2].(tea.KeyMsg); !alt.Alt || string(alt.Runes) != "b" {
+		t.Errorf("alt key: %+v", alt)
+	}
+	if len(steps[2].msgs) != 2 {
+		t.Errorf("type step sent %d keys, want 2", len(steps[2].msgs))
+	}
+	if click := steps[3].msgs[0].(tea.MouseMsg); click.X != 3 || click.Y != 4 || click.Button != tea.MouseButtonLeft {
+		t.Errorf("click step: %+v", click)
+	}
+	if wheel := steps[4].msgs[0].(tea.MouseMsg); wheel.Button != tea.MouseButtonWheelUp {
+		t.Errorf("wheel step: %+v", wheel)
+	}
+	if !steps[5].snapshot || steps[5].name != "after" {
+		t.Errorf("snapshot step: %+v", steps[5])
+	}
+}
+
+func TestParseScriptErrors(t *testing.T) {
+	for _, script := range []string{
+		"jump 1",
+		"size 80",
+		"size -1 24",
+		"key",
+		"key nosuchkey",
+		"wheel sideways 1 2",
+	} {
+		if _, err := parseScript(strings.NewReader(script)); err == nil {
+			t.Errorf("%q: expected an error", script)
+		}
+	}
+}
+
+// newScriptModel loads the branches of the current repository the way main does
+func newScriptModel(t *testing.T) model {
+	t.Helper()
+	m, err := newModel(DefaultConfig, NewGitService(), modelOptions{
+		count:     10,
+		authors:   []string{"all"},
+		sortOrder: DefaultSortOrder,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestScriptSwitchesBranch(t *testing.T) {
+	newTestRepo(t)
+	gitRun(t, "branch", "feature")
+
+	steps, err := parseScript(strings.NewReader("snapshot before\nkey down enter\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := playScript(newScriptModel(t), steps)
+	if result.Error != "" {
+		t.Fatal(result.Error)
+	}
+
+	before := result.Snapshots[0].State
+	if before.CurrentBranch != "main" || before.SelectedBranch != "main" {
+		t.Errorf("before: on %q with %q selected", before.CurrentBranch, before.SelectedBranch)
+	}
+	if result.State.CurrentBranch != "feature" {
+		t.Errorf("still on %q after switching", result.State.CurrentBranch)
+	}
+	if current := gitRun(t, "branch", "--show-current"); current != "feature" {
+		t.Errorf("repository is on %q", current)
+	}
+}
+
+func TestScriptCommitsAndSwitches(t *testing.T) {
+	newTestRepo(t)
+	gitRun(t, "branch", "feature")
+	if err := os.WriteFile("README", []byte("changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	script := "key down enter\nsnapshot modal\nkey tab\ntype Save work\nkey ctrl+s\n"
+	steps, err := parseScript(strings.NewReader(script))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := playScript(newScriptModel(t), steps)
+	if result.Error != "" {
+		t.Fatal(result.Error)
+	}
+
+	if modal := result.Snapshots[0].State; !modal.Modal || modal.CurrentBranch != "main" {
+		t.Errorf("expected the commit modal on main, got %+v", modal)
+	}
+	if result.State.Modal || result.State.CurrentBranch != "feature" {
+		t.Errorf("expected to end up on feature, got %+v", result.State)
+	}
+	if subject := gitRun(t, "log", "-1", "--format=%s", "main"); subject != "Save work" {
+		t.Errorf("main ends with %q", subject)
+	}
+	if status := gitRun(t, "status", "--porcelain"); status != "" {
+		t.Errorf("changes left behind: %q", status)
+	}
+}
+
+func TestScriptStashesAndSwitches(t *testing.T) {
+	newTestRepo(t)
+	gitRun(t, "branch", "feature")
+	if err := os.WriteFile("README", []byte("changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	steps, err := parseScript(strings.NewReader("key down enter ctrl+t\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := playScript(newScriptModel(t), steps)
+	if result.Error != "" {
+		t.Fatal(result.Error)
+	}
+
+	if result.State.Modal || result.State.CurrentBranch != "feature" {
+		t.Errorf("expected to end up on feature, got %+v", result.State)
+	}
+	if !strings.Contains(result.State.Message, "Stashed changes") {
+		t.Errorf("message is %q", result.State.Message)
+	}
+	if stash := gitRun(t, "stash", "list"); !strings.Contains(stash, "switching to feature") {
+		t.Errorf("stash list is %q", stash)
+	}
+}
+
+func TestScriptRefusesSwitchWhileRebasing(t *testing.T) {
+	newTestRepo(t)
+	gitRun(t, "branch", "feature")
+
+	steps, err := parseScript(strings.NewReader("key down enter\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newScriptModel(t)
+	m.rebaseEvents = make(chan tea.Msg)
+	result := playScript(m, steps)
+	if result.Error != "" {
+		t.Fatal(result.Error)
+	}
+
+	if result.State.CurrentBranch != "main" || result.State.Modal {
+		t.Errorf("switched while a rebase was running: %+v", result.State)
+	}
+	if result.State.Message != "Wait for the rebase to finish" {
+		t.Errorf("message is %q", result.State.Message)
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// contextHelp is the help for whatever currently has input: a short line for the footer and
+// grouped columns for the full overlay. It implements help.KeyMap.
+type contextHelp struct {
+	title string
+	short []key.Binding
+	full  [][]key.Binding
+}
+
+func (h contextHelp) ShortHelp() []key.Binding  { return h.short }
+func (h contextHelp) FullHelp() [][]key.Binding { return h.full }
+
+var (
+	helpOverlayStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(1, 2)
+)
+
+// newHelpModel returns a help view colored from the current theme
+func newHelpModel() help.Model {
+	h := help.New()
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary))
+	descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Muted))
+	sepStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Border))
+	h.Styles = help.Styles{
+		ShortKey:       keyStyle,
+		ShortDesc:      descStyle,
+		ShortSeparator: sepStyle,
+		Ellipsis:       sepStyle,
+		FullKey:        keyStyle,
+		FullDesc:       descStyle,
+		FullSeparator:  sepStyle,
+	}
+	return h
+}
+
+// activeHelp returns the bindings valid in the current view and focus
+func (m *model) activeHelp() contextHelp {
+	k := m.keys
+
+	if m.commitModal.IsVisible() {
+		return m.commitModal.helpKeys()
+	}
+
+	if m.commitLog.IsVisible() {
+		return m.commitLog.helpKeys(k.Help)
+	}
+
+	if m.activeTab == TabTags {
+		return contextHelp{
+			title: "Tags",
+			short: []key.Binding{k.Up, k.Down, tagsKeys.Checkout, tagsKeys.CreateBranch, k.BranchesTab, k.Help, k.Quit},
+			full: [][]key.Binding{
+				{k.Up, k.Down, k.PageUp, k.PageDown, k.Top, k.Bottom},
+				{tagsKeys.Checkout, tagsKeys.CreateBranch, k.BranchesTab},
+				{k.Refresh, k.ClearMessage, k.ClearLogs, k.Help, k.Quit},
+			},
+		}
+	}
+
+	var operation []key.Binding
+	if m.operation != OperationNone {
+		operation = []key.Binding{k.Continue, k.Skip, k.Abort}
+	}
+
+	switch {
+	case m.logViewer.IsEditing():
+		l := m.logViewer.keys
+		return contextHelp{
+			title: "Logs - search",
+			short: []key.Binding{l.Confirm, l.Cancel, typingHelp(k.Help)},
+			full:  [][]key.Binding{{l.Confirm, l.Cancel, typingHelp(k.Help)}},
+		}
+
+	case m.logViewer.focused:
+		l := m.logViewer.keys
+		return contextHelp{
+			title: "Logs",
+			short: []key.Binding{k.Up, k.Down, l.Search, l.ToggleDebug, l.Detail, l.Copy, k.CycleFocus, k.Help, k.Quit},
+			full: [][]key.Binding{
+				{k.Up, k.Down, k.PageUp, k.PageDown, k.Top, k.Bottom, k.CycleFocus},
+				{l.ToggleDebug, l.ToggleInfo, l.ToggleError, l.ToggleSuccess},
+				{l.Search, l.NextMatch, l.PrevMatch, l.Cancel, l.Detail, l.Copy},
+				{k.ClearLogs, k.ClearMessage, k.Help, k.Quit},
+			},
+		}
+
+	case m.diffViewer.focused:
+		return contextHelp{
+			title: "Diff preview",
+			short: []key.Binding{k.Up, k.Down, k.PageUp, k.PageDown, k.ToggleDiff, k.CycleFocus, k.Help, k.Quit},
+			full: [][]key.Binding{
+				{k.Up, k.Down, k.PageUp, k.PageDown, k.CycleFocus},
+				{k.ToggleDiff, k.CommitLog},
+				append(operation, k.Help, k.Quit),
+			},
+		}
+
+	case m.previewFocused:
+		return contextHelp{
+			title: "Commit preview",
+			short: []key.Binding{k.Up, k.Down, k.TogglePick, k.CherryPick, k.CycleFocus, k.Help, k.Quit},
+			full: [][]key.Binding{
+				{k.Up, k.Down, k.CycleFocus},
+				{k.TogglePick, k.CherryPick, k.ToggleDiff, k.CommitLog},
+				append(operation, k.Help, k.Quit),
+			},
+		}
+	}
+
+	return contextHelp{
+		title: "Branches",
+		short: append([]key.Binding{k.Up, k.Down, k.Switch, k.ToggleDiff, k.CommitLog}, append(operation, k.Help, k.Quit)...),
+		full: [][]key.Binding{
+			{k.Up, k.Down, k.PageUp, k.PageDown, k.HalfPageUp, k.HalfPageDown, k.Top, k.Bottom, k.CycleFocus},
+			{k.Switch, k.HistoryBack, k.HistoryForward, k.ToggleDiff, k.CommitLog, k.ToggleTree, k.CycleSort, k.InvertSort, k.TagsTab},
+			append([]key.Binding{k.Rebase, k.RebaseOnto, k.UndoRebase, k.Push, k.Pull, k.PullRebase, k.Fetch}, operation...),
+			{k.Refresh, k.ClearMessage, k.ClearLogs, k.Cancel, k.Help, k.Quit},
+		},
+	}
+}
+
+// helpKeys returns the modal bindings for its focused section
+func (m *CommitModal) helpKeys() contextHelp {
+	k := m.keys
+	// A failed commit can be retried or made without hooks; stashing drops out of the short help
+	short := []key.Binding{k.Commit, k.Stash, k.Cancel}
+	actions := []key.Binding{k.Commit, k.Mode, k.Sign, k.Stash, k.Cancel}
+	if m.failed {
+		short = []key.Binding{k.Commit, k.NoVerify, k.Cancel}
+		actions = []key.Binding{k.Commit, k.NoVerify, k.Mode, k.Sign, k.Stash, k.Cancel}
+	}
+	var output []key.Binding
+	if len(m.outputLines) > 0 {
+		output = []key.Binding{k.ScrollUp, k.ScrollDown}
+	}
+
+	if m.focusIndex == FocusGitStatus {
+		return contextHelp{
+			title: "Commit modal - changed files",
+			short: append([]key.Binding{k.Expand, k.Tab}, append(short, k.Help)...),
+			full: [][]key.Binding{
+				{k.Up, k.Down, k.Expand},
+				{k.Tab, k.ShiftTab},
+				append(actions, k.Help),
+				output,
+			},
+		}
+	}
+
+	if m.inFixupPicker() {
+		pick := key.NewBinding(
+			key.WithKeys(append(k.Up.Keys(), k.Down.Keys()...)...),
+			key.WithHelp(k.Up.Help().Key+"/"+k.Down.Help().Key, "pick commit"),
+		)
+		autosquash := key.NewBinding(
+			key.WithKeys(k.Expand.Keys()...),
+			key.WithHelp(k.Expand.Help().Key, "squash into it now"),
+		)
+		return contextHelp{
+			title: "Commit modal - fixup",
+			short: append([]key.Binding{pick, autosquash}, append(short, k.Help)...),
+			full: [][]key.Binding{
+				{pick, autosquash},
+				{k.Tab, k.ShiftTab},
+				append(actions, k.Help),
+				output,
+			},
+		}
+	}
+
+	moves := []key.Binding{k.Up, k.Down, k.Tab, k.ShiftTab}
+	if m.focusIndex == FocusSubject && len(m.history) > 0 {
+		recall := key.NewBinding(
+			key.WithKeys(append(k.Up.Keys(), k.Down.Keys()...)...),
+			key.WithHelp(k.Up.Help().Key+"/"+k.Down.Help().Key, "previous messages"),
+		)
+		moves = []key.Binding{recall, k.Tab, k.ShiftTab}
+	}
+
+	return contextHelp{
+		title: "Commit modal - message",
+		short: append(short, typingHelp(k.Help)),
+		full: [][]key.Binding{
+			moves,
+			append(actions, typingHelp(k.Help)),
+			output,
+		},
+	}
+}
+
+// helpKeys returns the commit log bindings for the list or the open commit
+func (v *CommitLogView) helpKeys(help key.Binding) contextHelp {
+	k := v.keys
+	if v.showDetail {
+		return contextHelp{
+			title: "Commit log - commit",
+			short: []key.Binding{k.Up, k.Down, k.PageUp, k.PageDown, k.Back, help},
+			full: [][]key.Binding{
+				{k.Up, k.Down, k.PageUp, k.PageDown},
+				{k.Back, help},
+			},
+		}
+	}
+
+	return contextHelp{
+		title: "Commit log",
+		short: []key.Binding{k.Up, k.Down, k.Open, k.Select, k.CherryPick, k.ToggleAll, k.Back, help},
+		full: [][]key.Binding{
+			{k.Up, k.Down, k.PageUp, k.PageDown},
+			{k.Open, k.Select, k.CherryPick, k.ToggleAll},
+			{k.Back, help},
+		},
+	}
+}
+
+// typingHelp drops the printable keys of a binding, which go to the text field while typing
+func typingHelp(b key.Binding) key.Binding {
+	var keys []string
+	for _, k := range b.Keys() {
+		if utf8.RuneCountInString(k) > 1 {
+			keys = append(keys, k)
+		}
+	}
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyHelp(keys), b.Help().Desc))
+}
+
+// opensHelp reports whether a key press should open the help overlay in the current view
+func (m *model) opensHelp(msg tea.KeyMsg) bool {
+	switch {
+	case m.commitModal.IsVisible():
+		if m.commitModal.focusIndex == FocusGitStatus {
+			return key.Matches(msg, m.commitModal.keys.Help)
+		}
+		return key.Matches(msg, typingHelp(m.commitModal.keys.Help))
+	case m.commitLog.IsVisible():
+		return key.Matches(msg, m.keys.Help)
+	case m.activeTab == TabTags && m.tagsView.IsEditing(), m.logViewer.IsEditing():
+		return key.Matches(msg, typingHelp(m.keys.Help))
+	case m.logViewer.detail:
+		return false
+	}
+	return key.Matches(msg, m.keys.Help)
+}
+
+// renderHelpOverlay shows every binding of the current context
+func (m *model) renderHelpOverlay() string {
+	ctx := m.activeHelp()
+	h := m.help
+	h.ShowAll = true
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Keys - "+ctx.title),
+		"",
+		h.View(ctx),
+		"",
+		helpStyle.Render("press any key to close"),
+	)
+
+	box := helpOverlayStyle.BorderForeground(lipgloss.Color(theme.Primary)).Render(content)
+	if m.width == 0 || m.height == 0 {
+		return box
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -42,14 +42,16 @@ func formatLastUsedTime(t time.Time) string {
 	}
 }
 
+// truncateString shortens s to maxLen characters, ending it with "..." when there is room
 func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
 	if maxLen <= 3 {
-		return s[:maxLen]
+		return string(runes[:maxLen])
 	}
-	return s[:maxLen-3] + "..."
+	return string(runes[:maxLen-3]) + "..."
 }
 
 func min(a, b int) int {
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a repository with one commit on main in a temp dir and makes it the
+// working directory for the rest of the test
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	// Keep the user's git config out of the tests
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	gitRun(t, "init", "-q", "-b", "main")
+	gitRun(t, "config", "user.name", "Tester")
+	gitRun(t, "config", "user.email", "tester@example.com")
+	commitFile(t, "README", "hello\n", "Initial commit")
+	return dir
+}
+
+// gitRun runs git in the working directory and returns its trimmed output, failing the test on error
+func gitRun(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// commitFile writes a file and commits it
+func commitFile(t *testing.T, name, content, message string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(".", name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, "add", name)
+	gitRun(t, "commit", "-q", "-m", message)
+}
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		s      string
+		maxLen int
+		want   string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"a longer subject", 10, "a longe..."},
+		{"abcdef", 3, "abc"},
+		{"abcdef", 0, ""},
+		{"ünïcödé täg", 8, "ünïcö..."},
+		{"✓✓✓✓", 2, "✓✓"},
+	}
+	for _, test := range tests {
+		if got := truncateString(test.s, test.maxLen); got != test.want {
+			t.Errorf("truncateString(%q, %d) = %q, want %q", test.s, test.maxLen, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// maxHistoryEntries caps how many switches the navigation history remembers
+const maxHistoryEntries = 100
+
+// NavigationHistory is a browser-style back/forward stack of branch switches
+type NavigationHistory struct {
+	Entries []string `json:"entries"`
+	Index   int      `json:"index"` // Position of the current branch in Entries
+}
+
+// Record notes a switch from one branch to another, dropping any forward history
+func (h *NavigationHistory) Record(from, to string) {
+	if from == to || to == "" || to == "HEAD" {
+		return
+	}
+
+	// Seed the stack with where we came from, including switches made outside the tool
+	h.sync(from)
+	h.push(to)
+
+	if len(h.Entries) > maxHistoryEntries {
+		drop := len(h.Entries) - maxHistoryEntries
+		h.Entries = h.Entries[drop:]
+		h.Index -= drop
+	}
+}
+
+// push truncates the forward history and appends a branch after the current position
+func (h *NavigationHistory) push(branch string) {
+	if len(h.Entries) > 0 {
+		h.Entries = h.Entries[:h.Index+1]
+	}
+	h.Entries = append(h.Entries, branch)
+	h.Index = len(h.Entries) - 1
+}
+
+// sync makes the checked out branch the current position if it was switched to outside the tool
+func (h *NavigationHistory) sync(current string) {
+	if current == "" || current == "HEAD" {
+		return
+	}
+	if len(h.Entries) == 0 || h.Entries[h.Index] != current {
+		h.push(current)
+	}
+}
+
+// prune removes branches that no longer exist and merges the duplicates that leaves behind
+func (h *NavigationHistory) prune(exists func(string) bool) {
+	var kept []string
+	index := 0
+	for i, branch := range h.Entries {
+		if !exists(branch) || (len(kept) > 0 && kept[len(kept)-1] == branch) {
+			continue
+		}
+		kept = append(kept, branch)
+		if i <= h.Index {
+			index = len(kept) - 1
+		}
+	}
+	h.Entries = kept
+	h.Index = index
+}
+
+// clamp keeps Index inside Entries, which a corrupt or hand-edited state file may not
+func (h *NavigationHistory) clamp() {
+	h.Index = min(max(h.Index, 0), max(len(h.Entries)-1, 0))
+}
+
+// Peek returns the branch steps entries away from the current one (negative is back)
+func (h *NavigationHistory) Peek(steps int, exists func(string) bool) (string, int, error) {
+	h.prune(exists)
+
+	target := h.Index + steps
+	switch {
+	case len(h.Entries) == 0:
+		return "", 0, fmt.Errorf("no navigation history yet")
+	case target < 0:
+		return "", 0, fmt.Errorf("can't go back %d, only %d earlier branches in history", -steps, h.Index)
+	case target >= len(h.Entries):
+		return "", 0, fmt.Errorf("can't go forward %d, only %d later branches in history", steps, len(h.Entries)-1-h.Index)
+	}
+	return h.Entries[target], target, nil
+}
+
+// localBranchExists reports whether refs/heads/<name> exists
+func (g *GitService) localBranchExists(branchName string) bool {
+	return gitCommand("show-ref", "--verify", "--quiet", "refs/heads/"+branchName).Run() == nil
+}
+
+func (g *GitService) loadHistory() (*NavigationHistory, string, error) {
+	path, err := g.repoStatePath("history.json")
+	if err != nil {
+		return nil, "", err
+	}
+	history := &NavigationHistory{}
+	if err := loadJSONFile(path, history); err != nil {
+		return nil, "", err
+	}
+	history.clamp()
+	return history, path, nil
+}
+
+// recordNavigation adds a switch to the persisted history
+func (g *GitService) recordNavigation(from, to string) error {
+	history, path, err := g.loadHistory()
+	if err != nil {
+		return err
+	}
+	history.Record(from, to)
+	return saveJSONFile(path, history)
+}
+
+// PeekHistory returns the branch a back (negative) or forward (positive) move would switch to
+func (g *GitService) PeekHistory(steps int) (string, error) {
+	history, _, err := g.loadHistory()
+	if err != nil {
+		return "", err
+	}
+
+	current, _ := g.GetCurrentBranch()
+	history.sync(current)
+
+	branch, _, err := history.Peek(steps, g.localBranchExists)
+	return branch, err
+}
+
+// NavigateHistory moves back (negative) or forward (positive) through the switch history
+// and checks out the branch found there, without adding a new history entry
+func (g *GitService) NavigateHistory(steps int) (string, error) {
+	history, path, err := g.loadHistory()
+	if err != nil {
+		return "", err
+	}
+
+	current, _ := g.GetCurrentBranch()
+	history.sync(current)
+
+	branch, index, err := history.Peek(steps, g.localBranchExists)
+	if err != nil {
+		return "", err
+	}
+
+	if err := g.checkoutBranch(branch); err != nil {
+		return "", err
+	}
+
+	history.Index = index
+	return branch, saveJSONFile(path, history)
+}
+
+// runHistoryCommand handles the "prev [N]" and "next [N]" commands without starting the TUI
+func runHistoryCommand(args []string) int {
+	steps := 1
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			fmt.Fprintf(os.Stderr, "Error: invalid step count %q\n", args[1])
+			return 2
+		}
+		steps = n
+	}
+
+	switch args[0] {
+	case "prev":
+		steps = -steps
+	case "next":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q (expected prev or next)\n", args[0])
+		return 2
+	}
+
+	branch, err := NewGitService().NavigateHistory(steps)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Switched to branch: %s\n", branch)
+	return 0
+}
+
+// navigateHistory moves back or forward through the switch history from the TUI
+func (m *model) navigateHistory(steps int) {
+	if m.worktreeBusy("switch branches") {
+		return
+	}
+	target, err := m.gitService.PeekHistory(steps)
+	if err != nil {
+		m.logError("History navigation failed: %v", err)
+		m.message = fmt.Sprintf("Error: %v", err)
+		return
+	}
+
+	hasChanges, err := m.gitService.HasUncommittedChanges()
+	if err != nil {
+		m.logError("Failed to check for uncommitted changes: %v", err)
+		m.message = fmt.Sprintf("Error: %v", err)
+		return
+	}
+	if hasChanges {
+		m.logInfo("Found uncommitted changes, showing commit modal")
+		m.pendingHistorySteps = steps
+		m.commitModal.Show(target, m.predictions[target])
+		return
+	}
+
+	branch, err := m.gitService.NavigateHistory(steps)
+	if err != nil {
+		m.logError("Failed to switch to %s: %v", target, err)
+		m.message = fmt.Sprintf("Error: %v", err)
+		return
+	}
+
+	m.logSuccess("Navigated to branch: %s", branch)
+	m.message = fmt.Sprintf("Switched to branch: %s", branch)
+	m.refreshAfterOperation()
+}
+
+// completeSwitch finishes a switch that waited on the commit modal, moving through history
+// instead of recording a new entry or detaching at a tag if that's how the switch was started
+func (m *model) completeSwitch(targetBranch string) error {
+	if tag := m.pendingTag; tag != "" {
+		m.pendingTag = ""
+		return m.gitService.CheckoutTag(tag)
+	}
+	if steps := m.pendingHistorySteps; steps != 0 {
+		m.pendingHistorySteps = 0
+		_, err := m.gitService.NavigateHistory(steps)
+		return err
+	}
+	return m.gitService.SwitchToBranch(targetBranch)
+}
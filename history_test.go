@@ -0,0 +1,88 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func allBranchesExist(string) bool { return true }
+
+func TestNavigationHistoryRecordAndPeek(t *testing.T) {
+	h := &NavigationHistory{}
+	h.Record("main", "feature")
+	h.Record("feature", "fix")
+
+	if !slices.Equal(h.Entries, []string{"main", "feature", "fix"}) || h.Index != 2 {
+		t.Fatalf("got %v at %d", h.Entries, h.Index)
+	}
+	if branch, index, err := h.Peek(-2, allBranchesExist); err != nil || branch != "main" || index != 0 {
+		t.Errorf("Peek(-2) = %q, %d, %v", branch, index, err)
+	}
+	if _, _, err := h.Peek(1, allBranchesExist); err == nil {
+		t.Error("expected an error going forward past the end")
+	}
+
+	// Going back and switching elsewhere drops the forward history
+	h.Index = 0
+	h.Record("main", "other")
+	if !slices.Equal(h.Entries, []string{"main", "other"}) || h.Index != 1 {
+		t.Errorf("got %v at %d", h.Entries, h.Index)
+	}
+}
+
+func TestNavigationHistorySyncsOutsideSwitches(t *testing.T) {
+	h := &NavigationHistory{Entries: []string{"main", "feature"}, Index: 1}
+	h.Record("elsewhere", "main")
+	if !slices.Equal(h.Entries, []string{"main", "feature", "elsewhere", "main"}) {
+		t.Errorf("got %v", h.Entries)
+	}
+}
+
+func TestNavigationHistoryPrune(t *testing.T) {
+	h := &NavigationHistory{Entries: []string{"main", "gone", "main", "feature"}, Index: 2}
+	branch, _, err := h.Peek(-1, func(name string) bool { return name != "gone" })
+	if err == nil {
+		t.Errorf("Peek(-1) = %q, want an error once the duplicates merge", branch)
+	}
+	if !slices.Equal(h.Entries, []string{"main", "feature"}) || h.Index != 0 {
+		t.Errorf("got %v at %d", h.Entries, h.Index)
+	}
+}
+
+func TestNavigationHistoryClamp(t *testing.T) {
+	tests := []struct {
+		entries []string
+		index   int
+		want    int
+	}{
+		{[]string{"a", "b"}, -3, 0},
+		{[]string{"a", "b"}, 5, 1},
+		{[]string{"a", "b"}, 1, 1},
+		{nil, -1, 0},
+		{nil, 4, 0},
+	}
+	for _, test := range tests {
+		h := &NavigationHistory{Entries: test.entries, Index: test.index}
+		h.clamp()
+		if h.Index != test.want {
+			t.Errorf("clamp(%v, %d) = %d, want %d", test.entries, test.index, h.Index, test.want)
+		}
+	}
+}
+
+func TestLoadHistoryClampsNegativeIndex(t *testing.T) {
+	newTestRepo(t)
+	git := NewGitService()
+	path, err := git.repoStatePath("history.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := saveJSONFile(path, &NavigationHistory{Entries: []string{"main"}, Index: -1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Used to panic indexing Entries[-1]
+	if _, err := git.PeekHistory(-1); err == nil {
+		t.Error("expected an error going back from the only entry")
+	}
+}
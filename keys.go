@@ -0,0 +1,336 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// KeyMap holds the key bindings of the main view
+type KeyMap struct {
+	Quit           key.Binding
+	Up             key.Binding
+	Down           key.Binding
+	PageUp         key.Binding
+	PageDown       key.Binding
+	HalfPageUp     key.Binding
+	HalfPageDown   key.Binding
+	Top            key.Binding
+	Bottom         key.Binding
+	Switch         key.Binding
+	Refresh        key.Binding
+	ClearLogs      key.Binding
+	ClearMessage   key.Binding
+	CycleFocus     key.Binding
+	ToggleDiff     key.Binding
+	CommitLog      key.Binding
+	BranchesTab    key.Binding
+	TagsTab        key.Binding
+	CycleSort      key.Binding
+	InvertSort     key.Binding
+	ToggleTree     key.Binding
+	TogglePick     key.Binding
+	CherryPick     key.Binding
+	Continue       key.Binding
+	Skip           key.Binding
+	Abort          key.Binding
+	Rebase         key.Binding
+	RebaseOnto     key.Binding
+	UndoRebase     key.Binding
+	HistoryBack    key.Binding
+	HistoryForward key.Binding
+	Push           key.Binding
+	Fetch          key.Binding
+	Pull           key.Binding
+	PullRebase     key.Binding
+	Cancel         key.Binding
+	Help           key.Binding
+}
+
+var mainKeys = KeyMap{
+	Quit:           key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+	Up:             key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+	Down:           key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+	PageUp:         key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "page up")),
+	PageDown:       key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdown", "page down")),
+	HalfPageUp:     key.NewBinding(key.WithKeys(), key.WithHelp("", "half page up")),
+	HalfPageDown:   key.NewBinding(key.WithKeys(), key.WithHelp("", "half page down")),
+	Top:            key.NewBinding(key.WithKeys("home", "g"), key.WithHelp("home/g", "top")),
+	Bottom:         key.NewBinding(key.WithKeys("end", "G"), key.WithHelp("end/G", "bottom")),
+	Switch:         key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "switch")),
+	Refresh:        key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+	ClearLogs:      key.NewBinding(key.WithKeys("l"), key.WithHelp("l", "clear logs")),
+	ClearMessage:   key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "clear message")),
+	CycleFocus:     key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "focus")),
+	ToggleDiff:     key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "diff")),
+	CommitLog:      key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "log")),
+	BranchesTab:    key.NewBinding(key.WithKeys("1"), key.WithHelp("1", "branches")),
+	TagsTab:        key.NewBinding(key.WithKeys("2"), key.WithHelp("2", "tags")),
+	CycleSort:      key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort")),
+	InvertSort:     key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "invert sort")),
+	ToggleTree:     key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "tree")),
+	TogglePick:     key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "select commit")),
+	CherryPick:     key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "cherry-pick")),
+	Continue:       key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "continue")),
+	Skip:           key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "skip")),
+	Abort:          key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "abort")),
+	Rebase:         key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "rebase")),
+	RebaseOnto:     key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "rebase onto")),
+	UndoRebase:     key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "undo rebase")),
+	HistoryBack:    key.NewBinding(key.WithKeys("[", "alt+left"), key.WithHelp("[", "back")),
+	HistoryForward: key.NewBinding(key.WithKeys("]", "alt+right"), key.WithHelp("]", "forward")),
+	Push:           key.NewBinding(key.WithKeys("P"), key.WithHelp("P", "push")),
+	Fetch:          key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "fetch")),
+	Pull:           key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "pull")),
+	PullRebase:     key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "pull --rebase")),
+	Cancel:         key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+	Help:           key.NewBinding(key.WithKeys("?", "f1"), key.WithHelp("?/f1", "help")),
+}
+
+// vimKeys are the extra keys of the "vim" keymap preset, added to the defaults
+var vimKeys = map[string][]string{
+	"page-up":        {"pgup", "ctrl+b"},
+	"page-down":      {"pgdown", "ctrl+f"},
+	"half-page-up":   {"ctrl+u"},
+	"half-page-down": {"ctrl+d"},
+	"history-back":   {"[", "alt+left", "ctrl+o"},
+	"modal.up":       {"up", "ctrl+p"},
+	"modal.down":     {"down", "ctrl+n"},
+}
+
+// namedBinding ties a key binding to the name it has in the config file
+type namedBinding struct {
+	name    string
+	binding *key.Binding
+}
+
+func (k *KeyMap) named() []namedBinding {
+	return []namedBinding{
+		{"quit", &k.Quit},
+		{"up", &k.Up},
+		{"down", &k.Down},
+		{"page-up", &k.PageUp},
+		{"page-down", &k.PageDown},
+		{"half-page-up", &k.HalfPageUp},
+		{"half-page-down", &k.HalfPageDown},
+		{"top", &k.Top},
+		{"bottom", &k.Bottom},
+		{"switch", &k.Switch},
+		{"refresh", &k.Refresh},
+		{"clear-logs", &k.ClearLogs},
+		{"clear-message", &k.ClearMessage},
+		{"cycle-focus", &k.CycleFocus},
+		{"toggle-diff", &k.ToggleDiff},
+		{"commit-log", &k.CommitLog},
+		{"branches-tab", &k.BranchesTab},
+		{"tags-tab", &k.TagsTab},
+		{"cycle-sort", &k.CycleSort},
+		{"invert-sort", &k.InvertSort},
+		{"toggle-tree", &k.ToggleTree},
+		{"toggle-pick", &k.TogglePick},
+		{"cherry-pick", &k.CherryPick},
+		{"continue", &k.Continue},
+		{"skip", &k.Skip},
+		{"abort", &k.Abort},
+		{"rebase", &k.Rebase},
+		{"rebase-onto", &k.RebaseOnto},
+		{"undo-rebase", &k.UndoRebase},
+		{"history-back", &k.HistoryBack},
+		{"history-forward", &k.HistoryForward},
+		{"push", &k.Push},
+		{"fetch", &k.Fetch},
+		{"pull", &k.Pull},
+		{"pull-rebase", &k.PullRebase},
+		{"cancel", &k.Cancel},
+		{"help", &k.Help},
+	}
+}
+
+func (k *CommitModalKeyMap) named() []namedBinding {
+	return []namedBinding{
+		{"modal.next-field", &k.Tab},
+		{"modal.prev-field", &k.ShiftTab},
+		{"modal.commit", &k.Commit},
+		{"modal.stash", &k.Stash},
+		{"modal.cancel", &k.Cancel},
+		{"modal.up", &k.Up},
+		{"modal.down", &k.Down},
+		{"modal.expand", &k.Expand},
+		{"modal.no-verify", &k.NoVerify},
+		{"modal.scroll-up", &k.ScrollUp},
+		{"modal.scroll-down", &k.ScrollDown},
+		{"modal.mode", &k.Mode},
+		{"modal.sign", &k.Sign},
+		{"modal.help", &k.Help},
+	}
+}
+
+// tableKeyMap drives the branch and tag tables from the main view's bindings
+func (k KeyMap) tableKeyMap() table.KeyMap {
+	return table.KeyMap{
+		LineUp:       k.Up,
+		LineDown:     k.Down,
+		PageUp:       k.PageUp,
+		PageDown:     k.PageDown,
+		HalfPageUp:   k.HalfPageUp,
+		HalfPageDown: k.HalfPageDown,
+		GotoTop:      k.Top,
+		GotoBottom:   k.Bottom,
+	}
+}
+
+// applyKeyConfig applies the keymap preset and per-binding overrides from the config,
+// then checks that no key is bound to two actions that are active at the same time
+func applyKeyConfig(preset string, overrides map[string][]string) error {
+	var bindings []namedBinding
+	for _, named := range [][]namedBinding{mainKeys.named(), commitModalKeys.named(), logKeys.named(), commitLogKeys.named(), tagsKeys.named()} {
+		bindings = append(bindings, named...)
+	}
+	byName := make(map[string]*key.Binding)
+	var names []string
+	for _, nb := range bindings {
+		byName[nb.name] = nb.binding
+		names = append(names, nb.name)
+	}
+
+	set := func(name string, keys []string) error {
+		binding, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("unknown key binding %q (expected one of %s)", name, strings.Join(names, ", "))
+		}
+		binding.SetKeys(keys...)
+		binding.SetHelp(keyHelp(keys), binding.Help().Desc)
+		binding.SetEnabled(len(keys) > 0)
+		return nil
+	}
+
+	switch preset {
+	case "", "default":
+	case "vim":
+		for name, keys := range vimKeys {
+			if err := set(name, keys); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown keymap %q (expected default or vim)", preset)
+	}
+
+	for name, keys := range overrides {
+		if err := set(name, keys); err != nil {
+			return err
+		}
+	}
+
+	return checkKeyConflicts()
+}
+
+// keyHelp shows a binding's keys the way the help line does, e.g. "↑/k"
+func keyHelp(keys []string) string {
+	shown := make([]string, 0, len(keys))
+	for _, k := range keys {
+		switch k {
+		case "up":
+			k = "↑"
+		case "down":
+			k = "↓"
+		case " ":
+			k = "space"
+		}
+		shown = append(shown, k)
+	}
+	return strings.Join(shown, "/")
+}
+
+// checkKeyConflicts reports every key bound to more than one action in the same context
+func checkKeyConflicts() error {
+	tagsGlobals := []namedBinding{
+		{"quit", &mainKeys.Quit},
+		{"branches-tab", &mainKeys.BranchesTab},
+		{"refresh", &mainKeys.Refresh},
+		{"clear-logs", &mainKeys.ClearLogs},
+		{"clear-message", &mainKeys.ClearMessage},
+		{"help", &mainKeys.Help},
+	}
+	tagsView := []namedBinding{
+		{"tags.checkout", &tagsKeys.Checkout},
+		{"tags.create-branch", &tagsKeys.CreateBranch},
+	}
+	tagsPrompt := []namedBinding{
+		{"tags.confirm", &tagsKeys.Confirm},
+		{"tags.cancel", &tagsKeys.Cancel},
+	}
+
+	logGlobals := []namedBinding{
+		{"quit", &mainKeys.Quit},
+		{"up", &mainKeys.Up},
+		{"down", &mainKeys.Down},
+		{"page-up", &mainKeys.PageUp},
+		{"page-down", &mainKeys.PageDown},
+		{"half-page-up", &mainKeys.HalfPageUp},
+		{"half-page-down", &mainKeys.HalfPageDown},
+		{"top", &mainKeys.Top},
+		{"bottom", &mainKeys.Bottom},
+		{"cycle-focus", &mainKeys.CycleFocus},
+		{"clear-logs", &mainKeys.ClearLogs},
+		{"help", &mainKeys.Help},
+	}
+
+	var conflicts []string
+	conflicts = append(conflicts, keyConflicts("main view", mainKeys.named())...)
+	conflicts = append(conflicts, keyConflicts("log pane", append(logGlobals, logKeys.named()...))...)
+	conflicts = append(conflicts, keyConflicts("commit modal", commitModalKeys.named())...)
+	conflicts = append(conflicts, keyConflicts("tags tab", append(tagsGlobals, tagsView...))...)
+	conflicts = append(conflicts, keyConflicts("branch name prompt", tagsPrompt)...)
+	conflicts = append(conflicts, keyConflicts("commit log", append(commitLogKeys.named(), namedBinding{"help", &mainKeys.Help}))...)
+
+	// Single characters in the modal would be swallowed instead of typed into the message
+	for _, nb := range commitModalKeys.named() {
+		if nb.name == "modal.expand" || nb.name == "modal.help" {
+			continue // Only active in the file list
+		}
+		for _, k := range nb.binding.Keys() {
+			if utf8.RuneCountInString(k) == 1 {
+				conflicts = append(conflicts, fmt.Sprintf("commit modal: %q can't be bound to %s, it is needed for typing", k, nb.name))
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("conflicting key bindings:\n  %s", strings.Join(conflicts, "\n  "))
+	}
+	return nil
+}
+
+func keyConflicts(context string, bindings []namedBinding) []string {
+	owners := make(map[string][]string)
+	for _, nb := range bindings {
+		for _, k := range nb.binding.Keys() {
+			owners[k] = append(owners[k], nb.name)
+		}
+	}
+
+	var conflicts []string
+	for k, names := range owners {
+		if len(names) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("%s: %q is bound to %s", context, k, strings.Join(names, ", ")))
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// shortHelp renders bindings as a "key: action • key: action" help line
+func shortHelp(bindings ...key.Binding) string {
+	var parts []string
+	for _, b := range bindings {
+		if b.Enabled() {
+			parts = append(parts, b.Help().Key+": "+b.Help().Desc)
+		}
+	}
+	return strings.Join(parts, " • ")
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// restoreKeys puts the default key bindings back after a test changes them
+func restoreKeys(t *testing.T) {
+	main, modal, logs, commitLog, tags := mainKeys, commitModalKeys, logKeys, commitLogKeys, tagsKeys
+	t.Cleanup(func() {
+		mainKeys, commitModalKeys, logKeys, commitLogKeys, tagsKeys = main, modal, logs, commitLog, tags
+	})
+}
+
+func TestApplyKeyConfigCommitLogAndTags(t *testing.T) {
+	restoreKeys(t)
+	err := applyKeyConfig("default", map[string][]string{
+		"commit-log.toggle-all": {"A"},
+		"tags.cancel":           {"ctrl+g"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	view := NewCommitLogView()
+	view.visible = true
+	if help := view.View(); !strings.Contains(help, "A: toggle all history") {
+		t.Errorf("commit log help doesn't show the configured key:\n%s", help)
+	}
+	if got := NewTagsView().keys.Cancel.Help().Key; got != "ctrl+g" {
+		t.Errorf("tags cancel key shown as %q", got)
+	}
+}
+
+func TestApplyKeyConfigCommitLogConflict(t *testing.T) {
+	restoreKeys(t)
+	err := applyKeyConfig("default", map[string][]string{"commit-log.open": {"p"}})
+	if err == nil || !strings.Contains(err.Error(), "commit log") {
+		t.Errorf("expected a commit log conflict, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Terminal heights below which panes are shrunk or collapsed to leave room for the table
+const (
+	fullLayoutHeight = 40 // Below this the log and diff panes shrink
+	logsMinHeight    = 30 // Below this the log pane is hidden
+	previewMinHeight = 22 // Below this the preview pane is hidden
+	minTableHeight   = 3
+)
+
+// previewVisible reports whether the terminal is tall enough for the commit/diff preview
+func (m *model) previewVisible() bool {
+	return m.height == 0 || m.height >= previewMinHeight
+}
+
+// logsVisible reports whether the terminal is tall enough for the log pane
+func (m *model) logsVisible() bool {
+	return m.height == 0 || m.height >= logsMinHeight
+}
+
+// applyLayout resizes every pane to the current terminal size
+func (m *model) applyLayout() {
+	logHeight, diffHeight := 8, 12
+	if m.height > 0 && m.height < fullLayoutHeight {
+		logHeight, diffHeight = 4, 6
+	}
+	m.logViewer.maxVisible = logHeight
+	m.logViewer.width = m.width
+	m.diffViewer.SetSize(m.width-4, diffHeight)
+
+	// Don't leave focus on a pane that is no longer shown
+	if !m.previewVisible() {
+		m.previewFocused = false
+		m.diffViewer.focused = false
+	}
+	if !m.logsVisible() {
+		m.logViewer.focused = false
+	}
+
+	m.tableManager.SetWidth(m.width)
+	if m.loadMissingColumnData() {
+		m.tableManager.RefreshRows(m.branches)
+	}
+	m.commitLog.SetSize(m.width, m.height)
+	m.commitModal.SetSize(m.width, m.height)
+	m.logDebug("Layout: %dx%d, preview=%v, logs=%v", m.width, m.height, m.previewVisible(), m.logsVisible())
+}
+
+// tableHeightFor returns how many lines a table can take between the sections rendered
+// above and below it, or 0 when the terminal size isn't known yet
+func (m *model) tableHeightFor(above, below []string) int {
+	if m.height == 0 {
+		return 0
+	}
+	used := lipgloss.Height(lipgloss.JoinVertical(lipgloss.Left, append(above, below...)...))
+	return max(m.height-used-2, minTableHeight) // 2 for the table border
+}
+
+// fitColumns picks the columns that fit in width, dropping the lowest priority ones first and
+// then shrinking the rest toward their minimum widths
+func fitColumns(columns []*branchColumn, width int) ([]*branchColumn, []table.Column) {
+	visible := append([]*branchColumn(nil), columns...)
+	widths := make([]int, len(visible))
+	for i, column := range visible {
+		widths[i] = column.Width
+	}
+
+	if width > 0 {
+		available := width - 2 // Table border
+		total := func(pick func(int) int) int {
+			sum := 0
+			for i := range visible {
+				sum += pick(i) + 2 // Cell padding
+			}
+			return sum
+		}
+
+		// Hide columns until their minimum widths fit
+		for len(visible) > 1 && total(func(i int) int { return visible[i].MinWidth }) > available {
+			drop := 0
+			for i, column := range visible {
+				if column.Priority >= visible[drop].Priority {
+					drop = i
+				}
+			}
+			visible = append(visible[:drop], visible[drop+1:]...)
+			widths = append(widths[:drop], widths[drop+1:]...)
+		}
+
+		// Shrink the least important columns first
+		excess := total(func(i int) int { return widths[i] }) - available
+		for priority := maxPriority(visible); excess > 0 && priority >= 0; priority-- {
+			for i, column := range visible {
+				if column.Priority != priority || excess <= 0 {
+					continue
+				}
+				shrink := min(excess, widths[i]-column.MinWidth)
+				widths[i] -= shrink
+				excess -= shrink
+			}
+		}
+		if excess > 0 {
+			widths[0] = max(widths[0]-excess, 1)
+		}
+	}
+
+	tableColumns := make([]table.Column, len(visible))
+	for i, column := range visible {
+		tableColumns[i] = table.Column{Title: column.Title, Width: widths[i]}
+	}
+	return visible, tableColumns
+}
+
+func maxPriority(columns []*branchColumn) int {
+	highest := 0
+	for _, column := range columns {
+		highest = max(highest, column.Priority)
+	}
+	return highest
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxLogFileSize = 10 << 20 // Larger log files are rotated to <name>.1 on startup
+	maxTraceStderr = 512      // Bytes of stderr kept per git command
+)
+
+// levelSuccess sits between info and warn so SUCCESS entries keep their own level in the file
+const levelSuccess = slog.Level(2)
+
+// fileLog writes JSON lines to the log file, or is nil when logging to a file is disabled
+var fileLog *slog.Logger
+
+// defaultLogPath returns where the log file lives when no -log-file flag is given
+func defaultLogPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "recent-branches", "recent-branches.log")
+}
+
+// openLogFile starts appending structured log entries to path. An empty path disables the log file.
+func openLogFile(path string) (*os.File, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %v", err)
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > maxLogFileSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return nil, fmt.Errorf("failed to rotate log file: %v", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %v", err)
+	}
+
+	fileLog = slog.New(slog.NewJSONHandler(file, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.LevelKey && attr.Value.Any() == levelSuccess {
+				return slog.String(slog.LevelKey, "SUCCESS")
+			}
+			return attr
+		},
+	}))
+	fileLog.Info("started", "pid", os.Getpid(), "args", os.Args[1:])
+	return file, nil
+}
+
+// writeLogFile records a log viewer entry in the log file
+func writeLogFile(level LogLevel, message string) {
+	if fileLog == nil {
+		return
+	}
+
+	switch level {
+	case DEBUG:
+		fileLog.Debug(message)
+	case ERROR:
+		fileLog.Error(message)
+	case SUCCESS:
+		fileLog.Log(context.Background(), levelSuccess, message)
+	default:
+		fileLog.Info(message)
+	}
+}
+
+// tracedCmd is a git command whose runs are recorded in the log file with their
+// arguments, duration, exit code and stderr
+type tracedCmd struct {
+	*exec.Cmd
+	started time.Time
+	stderr  *tailBuffer // End of the stderr of a run started by Start
+	mixed   bool        // Stdout and stderr go to the same writer
+}
+
+// tailBuffer keeps the last limit bytes written to it, starting at a whole character
+type tailBuffer struct {
+	data  []byte
+	limit int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	if len(b.data) > b.limit {
+		b.data = append([]byte(nil), b.data[len(b.data)-b.limit:]...)
+	}
+	// Drop what's left of a character cut off at the start
+	for len(b.data) > 0 && !utf8.RuneStart(b.data[0]) {
+		b.data = b.data[1:]
+	}
+	return len(p), nil
+}
+
+// sameWriter reports whether two writers are the same, like exec.Cmd does before sharing
+// one pipe for stdout and stderr
+func sameWriter(a, b io.Writer) (same bool) {
+	defer func() { recover() }() // Writers that can't be compared aren't the same
+	return a == b
+}
+
+// gitCommand returns a traced git command with the given arguments
+func gitCommand(args ...string) *tracedCmd {
+	return &tracedCmd{Cmd: exec.Command("git", args...)}
+}
+
+func (c *tracedCmd) Run() error {
+	var stderr bytes.Buffer
+	if c.Stderr == nil {
+		c.Stderr = &stderr
+	}
+	start := time.Now()
+	err := c.Cmd.Run()
+	c.trace(start, err, stderr.Bytes())
+	return err
+}
+
+func (c *tracedCmd) Output() ([]byte, error) {
+	start := time.Now()
+	output, err := c.Cmd.Output()
+
+	var stderr []byte
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		stderr = exitErr.Stderr
+	}
+	c.trace(start, err, stderr)
+	return output, err
+}
+
+// CombinedOutput records the output of failed runs in place of stderr, since the two are mixed
+func (c *tracedCmd) CombinedOutput() ([]byte, error) {
+	start := time.Now()
+	output, err := c.Cmd.CombinedOutput()
+
+	var stderr []byte
+	if err != nil {
+		stderr = output
+	}
+	c.trace(start, err, stderr)
+	return output, err
+}
+
+func (c *tracedCmd) Start() error {
+	c.started = time.Now()
+
+	// Keep the end of stderr for the trace while it still reaches the caller
+	if fileLog != nil {
+		c.stderr = &tailBuffer{limit: maxTraceStderr}
+		switch {
+		case c.Stderr == nil:
+			c.Stderr = c.stderr
+		case sameWriter(c.Stderr, c.Stdout):
+			c.mixed = true
+			c.Stdout = io.MultiWriter(c.Stdout, c.stderr)
+			c.Stderr = c.Stdout
+		default:
+			c.Stderr = io.MultiWriter(c.Stderr, c.stderr)
+		}
+	}
+
+	err := c.Cmd.Start()
+	if err != nil {
+		c.trace(c.started, err, nil)
+	}
+	return err
+}
+
+// Wait records the run started by Start. Like CombinedOutput, output mixed with stdout is
+// only recorded for failed runs.
+func (c *tracedCmd) Wait() error {
+	err := c.Cmd.Wait()
+	var stderr []byte
+	if c.stderr != nil && (err != nil || !c.mixed) {
+		stderr = c.stderr.data
+	}
+	c.trace(c.started, err, stderr)
+	return err
+}
+
+func (c *tracedCmd) trace(start time.Time, err error, stderr []byte) {
+	if fileLog == nil {
+		return
+	}
+
+	exitCode := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	} else if err != nil {
+		exitCode = -1 // Git couldn't be started
+	}
+
+	attrs := []any{
+		"argv", c.Args,
+		"duration_ms", float64(time.Since(start).Microseconds()) / 1000,
+		"exit_code", exitCode,
+	}
+	if c.Dir != "" {
+		attrs = append(attrs, "dir", c.Dir)
+	}
+	if text := strings.TrimSpace(string(stderr)); text != "" {
+		if len(text) > maxTraceStderr {
+			// Cut at the start of a character so the trace stays valid UTF-8
+			cut := maxTraceStderr
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			text = text[:cut] + "…"
+		}
+		attrs = append(attrs, "stderr", text)
+	}
+	if err != nil && exitErr == nil {
+		attrs = append(attrs, "error", err.Error())
+	}
+
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelWarn
+	}
+	fileLog.Log(context.Background(), level, "git", attrs...)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracedWaitRecordsStderr(t *testing.T) {
+	newTestRepo(t)
+	var trace bytes.Buffer
+	fileLog = slog.New(slog.NewJSONHandler(&trace, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	t.Cleanup(func() { fileLog = nil })
+
+	var output bytes.Buffer
+	cmd := gitCommand("rev-parse", "--verify", "no-such-branch")
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Wait(); err == nil {
+		t.Fatal("expected rev-parse to fail")
+	}
+
+	if !strings.Contains(output.String(), "fatal") {
+		t.Errorf("caller didn't get the output: %q", output.String())
+	}
+	if !strings.Contains(trace.String(), `"stderr":"fatal`) {
+		t.Errorf("trace is missing stderr: %s", trace.String())
+	}
+}
+
+func TestTailBufferKeepsTheEnd(t *testing.T) {
+	b := &tailBuffer{limit: 5}
+	b.Write([]byte("abc"))
+	b.Write([]byte("defg"))
+	if string(b.data) != "cdefg" {
+		t.Errorf("got %q, want %q", b.data, "cdefg")
+	}
+}
+
+func TestTailBufferKeepsWholeCharacters(t *testing.T) {
+	b := &tailBuffer{limit: 5}
+	b.Write([]byte("ab✓✓"))
+	if string(b.data) != "✓" {
+		t.Errorf("got %q, want %q", b.data, "✓")
+	}
+}
+
+func TestTraceTruncatesWholeCharacters(t *testing.T) {
+	var trace bytes.Buffer
+	fileLog = slog.New(slog.NewJSONHandler(&trace, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	t.Cleanup(func() { fileLog = nil })
+
+	stderr := "x" + strings.Repeat("é", maxTraceStderr)
+	gitCommand("status").trace(time.Now(), nil, []byte(stderr))
+	if strings.Contains(trace.String(), `\ufffd`) {
+		t.Errorf("trace split a character: %s", trace.String())
+	}
+	if !strings.Contains(trace.String(), "é…") {
+		t.Errorf("trace wasn't truncated: %s", trace.String())
+	}
+}
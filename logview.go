@@ -0,0 +1,532 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/termenv"
+)
+
+// LogLevel represents different types of log messages
+type LogLevel int
+
+const (
+	DEBUG LogLevel = iota
+	INFO
+	ERROR
+	SUCCESS
+)
+
+// String is the level as shown in the log pane
+func (l LogLevel) String() string {
+	switch l {
+	case DEBUG:
+		return "DEBUG"
+	case INFO:
+		return "INFO"
+	case ERROR:
+		return "ERROR"
+	case SUCCESS:
+		return "SUCC"
+	}
+	return "?"
+}
+
+// LogEntry represents a single log entry with level and timestamp
+type LogEntry struct {
+	Level       LogLevel
+	Message     string
+	Timestamp   time.Time
+	progressKey string // Phase of a git progress line, so later updates replace it
+}
+
+// LogAction is a request from the log pane that the model carries out
+type LogAction int
+
+const (
+	LogActionNone LogAction = iota
+	LogActionCopy
+)
+
+// LogViewer manages the scrollable log display
+type LogViewer struct {
+	entries      []LogEntry
+	selected     int // Index in entries of the entry under the cursor
+	scrollOffset int // First shown line, counted in entries that pass the filter
+	maxVisible   int
+	maxEntries   int
+	width        int // Terminal width, 0 until known
+	focused      bool
+	autoScroll   bool
+	hidden       map[LogLevel]bool // Levels filtered out of the pane
+	search       textinput.Model
+	searching    bool   // Search input has focus
+	query        string // Highlighted text, empty when not searching
+	detail       bool   // Popup with the full selected entry
+	action       LogAction
+	keys         LogKeyMap
+}
+
+// LogKeyMap holds the bindings of the focused log pane
+type LogKeyMap struct {
+	ToggleDebug   key.Binding
+	ToggleInfo    key.Binding
+	ToggleError   key.Binding
+	ToggleSuccess key.Binding
+	Search        key.Binding
+	NextMatch     key.Binding
+	PrevMatch     key.Binding
+	Detail        key.Binding
+	Copy          key.Binding
+	Confirm       key.Binding
+	Cancel        key.Binding
+}
+
+var logKeys = LogKeyMap{
+	ToggleDebug:   key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "toggle debug")),
+	ToggleInfo:    key.NewBinding(key.WithKeys("I"), key.WithHelp("I", "toggle info")),
+	ToggleError:   key.NewBinding(key.WithKeys("E"), key.WithHelp("E", "toggle errors")),
+	ToggleSuccess: key.NewBinding(key.WithKeys("O"), key.WithHelp("O", "toggle success")),
+	Search:        key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search")),
+	NextMatch:     key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "older match")),
+	PrevMatch:     key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "newer match")),
+	Detail:        key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "details")),
+	Copy:          key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy")),
+	Confirm:       key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "search")),
+	Cancel:        key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear search")),
+}
+
+func (k *LogKeyMap) named() []namedBinding {
+	return []namedBinding{
+		{"logs.toggle-debug", &k.ToggleDebug},
+		{"logs.toggle-info", &k.ToggleInfo},
+		{"logs.toggle-error", &k.ToggleError},
+		{"logs.toggle-success", &k.ToggleSuccess},
+		{"logs.search", &k.Search},
+		{"logs.next-match", &k.NextMatch},
+		{"logs.prev-match", &k.PrevMatch},
+		{"logs.detail", &k.Detail},
+		{"logs.copy", &k.Copy},
+	}
+}
+
+var (
+	logMatchStyle = lipgloss.NewStyle().
+			Bold(true)
+
+	logDetailStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			Padding(1, 2)
+)
+
+// NewLogViewer returns a log pane that keeps the last maxEntries entries
+func NewLogViewer(maxEntries int) *LogViewer {
+	search := textinput.New()
+	search.Prompt = "/"
+	search.Placeholder = "search"
+	search.Width = 30
+
+	return &LogViewer{
+		entries:    make([]LogEntry, 0),
+		maxVisible: 8,
+		maxEntries: maxEntries,
+		autoScroll: true,
+		hidden:     make(map[LogLevel]bool),
+		search:     search,
+		keys:       logKeys,
+	}
+}
+
+// Add appends an entry, dropping the oldest beyond the scrollback limit
+func (lv *LogViewer) Add(entry LogEntry) {
+	lv.entries = append(lv.entries, entry)
+	if len(lv.entries) > lv.maxEntries {
+		lv.entries = lv.entries[len(lv.entries)-lv.maxEntries:]
+		lv.selected = max(lv.selected-1, 0)
+	}
+
+	// Follow new entries unless the user moved away from the bottom
+	if lv.autoScroll {
+		lv.selectLast()
+	}
+}
+
+func (lv *LogViewer) Clear() {
+	lv.entries = make([]LogEntry, 0)
+	lv.selected = 0
+	lv.scrollOffset = 0
+	lv.autoScroll = true
+	lv.detail = false
+}
+
+// shown returns the indexes of the entries that pass the level filter
+func (lv *LogViewer) shown() []int {
+	shown := make([]int, 0, len(lv.entries))
+	for i, entry := range lv.entries {
+		if !lv.hidden[entry.Level] {
+			shown = append(shown, i)
+		}
+	}
+	return shown
+}
+
+// position returns where the selected entry is among the shown ones, or the closest shown
+// entry before it when it is filtered out
+func (lv *LogViewer) position(shown []int) int {
+	pos := 0
+	for i, index := range shown {
+		if index <= lv.selected {
+			pos = i
+		}
+	}
+	return pos
+}
+
+// move moves the cursor by delta shown entries
+func (lv *LogViewer) move(delta int) {
+	shown := lv.shown()
+	if len(shown) == 0 {
+		return
+	}
+	pos := min(max(lv.position(shown)+delta, 0), len(shown)-1)
+	lv.selected = shown[pos]
+	lv.autoScroll = pos == len(shown)-1
+}
+
+func (lv *LogViewer) selectLast() {
+	if shown := lv.shown(); len(shown) > 0 {
+		lv.selected = shown[len(shown)-1]
+	}
+}
+
+func (lv *LogViewer) ScrollUp() {
+	lv.move(-1)
+}
+
+func (lv *LogViewer) ScrollDown() {
+	lv.move(1)
+}
+
+func (lv *LogViewer) ToggleFocus() {
+	lv.focused = !lv.focused
+}
+
+// toggleLevel shows or hides the entries of one level
+func (lv *LogViewer) toggleLevel(level LogLevel) {
+	lv.hidden[level] = !lv.hidden[level]
+	if lv.autoScroll {
+		lv.selectLast()
+	}
+}
+
+// matches reports whether an entry contains the search text, ignoring case
+func (lv *LogViewer) matches(entry LogEntry) bool {
+	return lv.query != "" && strings.Contains(strings.ToLower(entry.Message), strings.ToLower(lv.query))
+}
+
+// findMatch moves the cursor to the next (dir 1) or previous (dir -1) matching entry,
+// wrapping around, and reports whether there was one
+func (lv *LogViewer) findMatch(dir int) bool {
+	shown := lv.shown()
+	pos := lv.position(shown)
+	for step := 1; step <= len(shown); step++ {
+		i := ((pos+dir*step)%len(shown) + len(shown)) % len(shown)
+		if lv.matches(lv.entries[shown[i]]) {
+			lv.selected = shown[i]
+			lv.autoScroll = i == len(shown)-1
+			return true
+		}
+	}
+	return false
+}
+
+// matchCount counts the shown entries that contain the search text
+func (lv *LogViewer) matchCount() int {
+	count := 0
+	for _, index := range lv.shown() {
+		if lv.matches(lv.entries[index]) {
+			count++
+		}
+	}
+	return count
+}
+
+// SelectedEntry returns the entry under the cursor
+func (lv *LogViewer) SelectedEntry() (LogEntry, bool) {
+	shown := lv.shown()
+	if len(shown) == 0 {
+		return LogEntry{}, false
+	}
+	return lv.entries[shown[lv.position(shown)]], true
+}
+
+// IsEditing reports whether the search input has focus
+func (lv *LogViewer) IsEditing() bool {
+	return lv.searching
+}
+
+func (lv *LogViewer) GetAction() LogAction {
+	return lv.action
+}
+
+func (lv *LogViewer) ClearAction() {
+	lv.action = LogActionNone
+}
+
+// Update handles a key while the pane has focus and reports whether the key was used
+func (lv *LogViewer) Update(msg tea.KeyMsg) (bool, tea.Cmd) {
+	k := lv.keys
+
+	// Any key closes the detail popup
+	if lv.detail {
+		if key.Matches(msg, k.Copy) {
+			lv.action = LogActionCopy
+		} else {
+			lv.detail = false
+		}
+		return true, nil
+	}
+
+	if lv.searching {
+		switch {
+		case key.Matches(msg, k.Confirm):
+			lv.searching = false
+			lv.search.Blur()
+			lv.query = strings.TrimSpace(lv.search.Value())
+			if entry, ok := lv.SelectedEntry(); ok && !lv.matches(entry) {
+				lv.findMatch(-1)
+			}
+			return true, nil
+		case key.Matches(msg, k.Cancel):
+			lv.searching = false
+			lv.search.Blur()
+			return true, nil
+		}
+		var cmd tea.Cmd
+		lv.search, cmd = lv.search.Update(msg)
+		return true, cmd
+	}
+
+	nav := mainKeys
+	switch {
+	case key.Matches(msg, nav.Up):
+		lv.move(-1)
+	case key.Matches(msg, nav.Down):
+		lv.move(1)
+	case key.Matches(msg, nav.PageUp), key.Matches(msg, nav.HalfPageUp):
+		lv.move(-lv.maxVisible)
+	case key.Matches(msg, nav.PageDown), key.Matches(msg, nav.HalfPageDown):
+		lv.move(lv.maxVisible)
+	case key.Matches(msg, nav.Top):
+		lv.move(-len(lv.entries))
+	case key.Matches(msg, nav.Bottom):
+		lv.move(len(lv.entries))
+	case key.Matches(msg, k.ToggleDebug):
+		lv.toggleLevel(DEBUG)
+	case key.Matches(msg, k.ToggleInfo):
+		lv.toggleLevel(INFO)
+	case key.Matches(msg, k.ToggleError):
+		lv.toggleLevel(ERROR)
+	case key.Matches(msg, k.ToggleSuccess):
+		lv.toggleLevel(SUCCESS)
+	case key.Matches(msg, k.Search):
+		lv.searching = true
+		lv.search.SetValue(lv.query)
+		lv.search.CursorEnd()
+		return true, lv.search.Focus()
+	case key.Matches(msg, k.NextMatch) && lv.query != "":
+		lv.findMatch(-1) // Newest entries are at the bottom, so searches go upwards
+	case key.Matches(msg, k.PrevMatch) && lv.query != "":
+		lv.findMatch(1)
+	case key.Matches(msg, k.Cancel) && lv.query != "":
+		lv.query = ""
+	case key.Matches(msg, k.Detail):
+		_, lv.detail = lv.SelectedEntry()
+	case key.Matches(msg, k.Copy):
+		if _, ok := lv.SelectedEntry(); ok {
+			lv.action = LogActionCopy
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// Title is the pane heading with the active filter and search
+func (lv *LogViewer) Title() string {
+	title := "Debug Logs:"
+	if lv.focused {
+		title = "Debug Logs: [FOCUSED - ↑↓ to scroll]"
+	}
+	parts := []string{logTitleStyle.Render(title)}
+
+	var hidden []string
+	for _, level := range []LogLevel{DEBUG, INFO, ERROR, SUCCESS} {
+		if lv.hidden[level] {
+			hidden = append(hidden, level.String())
+		}
+	}
+	if len(hidden) > 0 {
+		parts = append(parts, timestampStyle.Render("hiding "+strings.Join(hidden, ", ")))
+	}
+
+	switch {
+	case lv.searching:
+		parts = append(parts, lv.search.View())
+	case lv.query != "":
+		parts = append(parts, timestampStyle.Render(fmt.Sprintf("/%s (%d matches)", lv.query, lv.matchCount())))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (lv *LogViewer) View() string {
+	containerStyle := logContainerStyle
+	if lv.focused {
+		containerStyle = logFocusedStyle
+	}
+
+	shown := lv.shown()
+	if len(shown) == 0 {
+		emptyMsg := "No debug logs yet..."
+		if len(lv.entries) > 0 {
+			emptyMsg = "All entries are filtered out"
+		}
+		return containerStyle.Height(lv.maxVisible).Render(timestampStyle.Render(emptyMsg))
+	}
+
+	// Keep the cursor on screen
+	pos := lv.position(shown)
+	if pos < lv.scrollOffset {
+		lv.scrollOffset = pos
+	}
+	if pos >= lv.scrollOffset+lv.maxVisible {
+		lv.scrollOffset = pos - lv.maxVisible + 1
+	}
+	lv.scrollOffset = min(max(lv.scrollOffset, 0), max(len(shown)-lv.maxVisible, 0))
+
+	// Messages are cut to the pane width; the detail popup has the full text
+	messageWidth := 0
+	if lv.width > 0 {
+		messageWidth = max(lv.width-containerStyle.GetHorizontalFrameSize()-len("> 15:04:05 DEBUG "), 10)
+	}
+
+	var lines []string
+	for _, index := range shown[lv.scrollOffset:min(lv.scrollOffset+lv.maxVisible, len(shown))] {
+		entry := lv.entries[index]
+		style := logLevelStyle(entry.Level)
+
+		cursor := "  "
+		if lv.focused && index == shown[pos] {
+			cursor = "> "
+		}
+
+		message := strings.ReplaceAll(entry.Message, "\n", " ⏎ ")
+		if messageWidth > 0 {
+			message = runewidth.Truncate(message, messageWidth, "…")
+		}
+
+		line := fmt.Sprintf("%s%s %s %s",
+			cursor,
+			timestampStyle.Render(entry.Timestamp.Format("15:04:05")),
+			style.Render(fmt.Sprintf("%-5s", entry.Level)),
+			highlightMatches(message, lv.query, style))
+		lines = append(lines, line)
+	}
+
+	// Pad with empty lines if needed
+	for len(lines) < lv.maxVisible {
+		lines = append(lines, "")
+	}
+
+	return containerStyle.Height(lv.maxVisible).Render(strings.Join(lines, "\n"))
+}
+
+func logLevelStyle(level LogLevel) lipgloss.Style {
+	switch level {
+	case DEBUG:
+		return debugStyle
+	case ERROR:
+		return logErrorStyle
+	case SUCCESS:
+		return logSuccessStyle
+	}
+	return infoStyle
+}
+
+// highlightMatches renders text in style with every occurrence of query, ignoring case, highlighted
+func highlightMatches(text, query string, style lipgloss.Style) string {
+	lower := strings.ToLower(text)
+	if query == "" || len(lower) != len(text) {
+		return style.Render(text)
+	}
+	query = strings.ToLower(query)
+
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, query)
+		if i < 0 {
+			break
+		}
+		b.WriteString(style.Render(text[:i]))
+		b.WriteString(logMatchStyle.Render(text[i : i+len(query)]))
+		text, lower = text[i+len(query):], lower[i+len(query):]
+	}
+	b.WriteString(style.Render(text))
+	return b.String()
+}
+
+// DetailView shows the selected entry in full, centered in a width x height screen
+func (lv *LogViewer) DetailView(width, height int) string {
+	entry, _ := lv.SelectedEntry()
+	style := logLevelStyle(entry.Level)
+
+	textWidth := 80
+	if width > 0 {
+		textWidth = min(textWidth, max(width-10, 20))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Log entry"),
+		"",
+		labelStyle.Render("Time:  ")+entry.Timestamp.Format("2006-01-02 15:04:05.000"),
+		labelStyle.Render("Level: ")+style.Render(entry.Level.String()),
+		"",
+		lipgloss.NewStyle().Width(textWidth).Render(highlightMatches(entry.Message, lv.query, style)),
+		"",
+		helpStyle.Render(shortHelp(lv.keys.Copy)+" • any key: close"),
+	)
+
+	box := logDetailStyle.Render(content)
+	if width == 0 || height == 0 {
+		return box
+	}
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// handleLogAction carries out a request from the log pane
+func (m *model) handleLogAction() {
+	switch m.logViewer.GetAction() {
+	case LogActionCopy:
+		if entry, ok := m.logViewer.SelectedEntry(); ok {
+			m.message = copyToClipboard(entry.Message)
+		}
+	}
+	m.logViewer.ClearAction()
+}
+
+// copyToClipboard copies text with the system clipboard, falling back to the terminal's
+// OSC 52 escape sequence (which also works over SSH), and returns a status message
+func copyToClipboard(text string) string {
+	if err := clipboard.WriteAll(text); err == nil {
+		return "Copied log entry to the clipboard"
+	}
+	termenv.Copy(text)
+	return "Copied log entry through the terminal (OSC 52)"
+}
@@ -4,138 +4,124 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-// LogLevel represents different types of log messages
-type LogLevel int
-
-const (
-	DEBUG LogLevel = iota
-	INFO
-	ERROR
-	SUCCESS
-)
-
-// LogEntry represents a single log entry with level and timestamp
-type LogEntry struct {
-	Level     LogLevel
-	Message   string
-	Timestamp time.Time
-}
-
-// LogViewer manages the scrollable log display
-type LogViewer struct {
-	entries      []LogEntry
-	scrollOffset int
-	maxVisible   int
-	maxEntries   int
-	focused      bool
-	autoScroll   bool
-}
-
-func NewLogViewer() *LogViewer {
-	return &LogViewer{
-		entries:      make([]LogEntry, 0),
-		scrollOffset: 0,
-		maxVisible:   8,
-		maxEntries:   50,
-		focused:      false,
-		autoScroll:   true,
-	}
-}
-
 var (
 	// Styles
 	titleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205")).
 			Bold(true).
 			Padding(0, 1)
 
 	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
 			Italic(true)
 
 	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
 			Bold(true)
 
 	successStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("42")).
 			Bold(true).
 			Padding(0, 1)
 
 	// Log styles
 	logContainerStyle = lipgloss.NewStyle().
 				BorderStyle(lipgloss.NormalBorder()).
-				BorderForeground(lipgloss.Color("240")).
 				Padding(0, 1).
 				Height(8)
 
 	logTitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205")).
 			Bold(true)
 
-	debugStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240"))
+	debugStyle = lipgloss.NewStyle()
 
-	infoStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("39"))
+	infoStyle = lipgloss.NewStyle()
 
-	logErrorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196"))
+	logErrorStyle = lipgloss.NewStyle()
 
-	logSuccessStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("42"))
+	logSuccessStyle = lipgloss.NewStyle()
 
-	timestampStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
+	timestampStyle = lipgloss.NewStyle()
 
 	logFocusedStyle = lipgloss.NewStyle().
 			BorderStyle(lipgloss.NormalBorder()).
-			BorderForeground(lipgloss.Color("39")).
 			Padding(0, 1).
 			Height(8)
 
 	// Commit preview styles
 	commitContainerStyle = lipgloss.NewStyle().
 				BorderStyle(lipgloss.NormalBorder()).
-				BorderForeground(lipgloss.Color("240")).
+				Padding(0, 1).
+				Height(6)
+
+	commitFocusedStyle = lipgloss.NewStyle().
+				BorderStyle(lipgloss.NormalBorder()).
 				Padding(0, 1).
 				Height(6)
 
 	commitTitleStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("205")).
 				Bold(true)
 
-	commitHashStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("39"))
+	commitHashStyle = lipgloss.NewStyle()
 
-	commitAuthorStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("226"))
+	commitAuthorStyle = lipgloss.NewStyle()
 
-	commitTimeStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
+	commitTimeStyle = lipgloss.NewStyle()
 )
 
 type model struct {
-	tableManager    *TableManager
-	gitService      *GitService
-	commitModal     *CommitModal
-	logViewer       *LogViewer
-	branches        []Branch
-	selectedCommits []Commit
-	err             error
-	count           int
-	message         string
-	quitting        bool
-	includeRemote   bool
-	authors         []string
-	logs            []string // Keep for backward compatibility
+	tableManager        *TableManager
+	gitService          *GitService
+	commitModal         *CommitModal
+	logViewer           *LogViewer
+	diffViewer          *DiffViewer
+	commitLog           *CommitLogView
+	previewMode         PreviewMode
+	previewFocused      bool
+	previewCursor       int
+	previewPicks        map[string]bool // Full hashes selected in the commit preview
+	operation           GitOperation    // Cherry-pick or similar waiting on conflict resolution
+	conflictFiles       []string
+	rebaseSource        *Branch // Branch waiting for an "onto" target to be chosen
+	remoteEvents        chan tea.Msg
+	commitEvents        chan tea.Msg  // Output of a commit running from the modal
+	rebaseEvents        chan tea.Msg  // Output of a rebase running in the background
+	fetchInterval       time.Duration // Zero disables background fetching
+	remoteUpdates       map[string]RemoteUpdate
+	backgroundFetching  bool
+	predictionCache     *predictionCache
+	predictions         map[string]*SwitchPrediction // By branch name, for predictionState
+	predictionState     string                       // Repository state the predictions hold for
+	predictionChecked   string                       // Selected branch whose prediction is current or on its way
+	pendingHistorySteps int                          // Back/forward move waiting on the commit modal
+	pendingTag          string                       // Tag checkout waiting on the commit modal
+	activeTab           ViewTab
+	tagsView            *TagsView
+	sortOrder           SortOrder
+	keys                KeyMap
+	help                help.Model
+	showHelp            bool          // Full help overlay for the current view
+	screen              *screenLayout // Where the panes were last drawn, for mouse clicks
+	lastClickRow        int
+	lastClickAt         time.Time
+	width               int // Terminal size, 0 until the first WindowSizeMsg
+	height              int
+	detachedHead        bool
+	branches            []Branch
+	selectedCommits     []Commit
+	err                 error
+	count               int
+	message             string
+	quitting            bool
+	includeRemote       bool
+	authors             []string
+	logs                []string // Keep for backward compatibility
 }
 
 func main() {
@@ -143,9 +129,55 @@ func main() {
 		count         = flag.Int("n", 10, "Number of branches to show")
 		includeRemote = flag.Bool("remote", false, "Include remote branches")
 		authorFlag    = flag.String("author", "", "Filter by author(s). Use 'mine' for your commits, 'all' for everyone, or comma-separated usernames")
+		fetchInterval = flag.Duration("fetch-interval", 0, "Fetch in the background at this interval (e.g. 5m), 0 to disable")
+		configPath    = flag.String("config", defaultConfigPath(), "Path to the config file")
+		logFile       = flag.String("log-file", defaultLogPath(), "Append JSON log lines and a trace of git commands to this file (empty to disable)")
+		themeFlag     = flag.String("theme", "", "Color theme: auto, dark, light, high-contrast, mono or one defined in the config file (NO_COLOR forces mono)")
+		mouse         = flag.Bool("mouse", true, "Enable mouse support (disable to keep the terminal's own text selection)")
+		repoDir       = flag.String("repo", "", "Run against the repository in this directory instead of the current one")
+		script        = flag.String("script", "", "Drive the UI with a script of key presses and window sizes instead of a terminal, then print the final view and state as JSON (- for stdin)")
+		sortFlag      = flag.String("sort", "", "Sort by last-used, commit-date, name, author, ahead or frecency, optionally with :asc or :desc (remembered per repository)")
 	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [prev [N] | next [N]]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
+	// Scripts are found relative to where we were started, not the repository
+	if *script != "" && *script != "-" {
+		if path, err := filepath.Abs(*script); err == nil {
+			*script = path
+		}
+	}
+
+	// Scripted runs only log to a file when asked to, so they don't fill up the user's log
+	if *script != "" {
+		explicit := false
+		flag.Visit(func(f *flag.Flag) { explicit = explicit || f.Name == "log-file" })
+		if !explicit {
+			*logFile = ""
+		}
+	}
+
+	if *repoDir != "" {
+		if err := os.Chdir(*repoDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	if file, err := openLogFile(*logFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else if file != nil {
+		defer file.Close()
+	}
+
+	// Navigate the switch history without starting the TUI
+	if args := flag.Args(); len(args) > 0 {
+		os.Exit(runHistoryCommand(args))
+	}
+
 	// Parse authors
 	var authors []string
 	if *authorFlag != "" {
@@ -166,32 +198,62 @@ func main() {
 		authors = []string{"mine"}
 	}
 
-	m := model{
-		count:           *count,
-		includeRemote:   *includeRemote,
-		authors:         authors,
-		tableManager:    NewTableManager(),
-		gitService:      NewGitService(),
-		commitModal:     NewCommitModal(),
-		logViewer:       NewLogViewer(),
-		selectedCommits: []Commit{},
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
 	}
 
-	// Add initial startup logging
-	m.logInfo("Application started - Recent Branches v1.0")
-	m.logDebug("Configuration: count=%d, includeRemote=%v, authors=%v", m.count, m.includeRemote, m.authors)
+	if *themeFlag != "" {
+		config.Theme = *themeFlag
+	}
+	selectedTheme, err := resolveTheme(config.Theme, config.Themes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	applyTheme(selectedTheme)
 
-	if err := m.loadBranches(); err != nil {
-		m.logError("Failed to load branches: %v", err)
+	if err := applyKeyConfig(config.Keymap, config.Keys); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	gitService := NewGitService()
+	sortOrder := gitService.LoadSortOrder()
+	if *sortFlag != "" {
+		order, err := ParseSortOrder(*sortFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		sortOrder = order
+		if err := gitService.SaveSortOrder(sortOrder); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save sort order: %v\n", err)
+		}
+	}
+
+	m, err := newModel(config, gitService, modelOptions{
+		count:         *count,
+		includeRemote: *includeRemote,
+		authors:       authors,
+		sortOrder:     sortOrder,
+		fetchInterval: *fetchInterval,
+	})
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	m.logSuccess("Successfully loaded %d branches", len(m.branches))
-	m.setupTable()
-	m.logDebug("Table setup complete")
+	if *script != "" {
+		os.Exit(runScript(m, *script))
+	}
 
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	options := []tea.ProgramOption{tea.WithAltScreen()}
+	if *mouse {
+		options = append(options, tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(m, options...)
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
 		os.Exit(1)
@@ -231,128 +293,47 @@ func (m *model) addLogEntry(level LogLevel, msg string, args ...interface{}) {
 		Timestamp: time.Now(),
 	}
 
-	m.logViewer.entries = append(m.logViewer.entries, entry)
-
-	// Keep only maxEntries
-	if len(m.logViewer.entries) > m.logViewer.maxEntries {
-		m.logViewer.entries = m.logViewer.entries[1:]
-		if m.logViewer.scrollOffset > 0 {
-			m.logViewer.scrollOffset--
-		}
-	}
-
-	// Auto-scroll to bottom if enabled
-	if m.logViewer.autoScroll {
-		maxScroll := len(m.logViewer.entries) - m.logViewer.maxVisible
-		if maxScroll > 0 {
-			m.logViewer.scrollOffset = maxScroll
-		}
-	}
-}
-
-func (m *model) clearLogs() {
-	m.logViewer.entries = make([]LogEntry, 0)
-	m.logViewer.scrollOffset = 0
-	m.logViewer.autoScroll = true
+	m.logViewer.Add(entry)
+	writeLogFile(level, entry.Message)
 }
 
-// LogViewer methods
-func (lv *LogViewer) ScrollUp() {
-	if lv.scrollOffset > 0 {
-		lv.scrollOffset--
-		lv.autoScroll = false
+// logProgress logs a line of git progress output. Percentage updates for the same
+// phase (e.g. "Receiving objects: 45%") replace the previous line instead of piling up.
+func (m *model) logProgress(line string) {
+	progressKey := ""
+	if i := strings.Index(line, ":"); i >= 0 && strings.Contains(line, "%") {
+		progressKey = line[:i]
 	}
-}
 
-func (lv *LogViewer) ScrollDown() {
-	maxScroll := len(lv.entries) - lv.maxVisible
-	if maxScroll > 0 && lv.scrollOffset < maxScroll {
-		lv.scrollOffset++
-		// Check if we're at the bottom to re-enable auto-scroll
-		if lv.scrollOffset == maxScroll {
-			lv.autoScroll = true
-		}
+	entries := m.logViewer.entries
+	if n := len(entries); n > 0 && progressKey != "" && entries[n-1].progressKey == progressKey {
+		entries[n-1].Message = line
+		entries[n-1].Timestamp = time.Now()
+		return
 	}
-}
 
-func (lv *LogViewer) ToggleFocus() {
-	lv.focused = !lv.focused
+	m.addLogEntry(INFO, "%s", line)
+	m.logViewer.entries[len(m.logViewer.entries)-1].progressKey = progressKey
 }
 
-func (lv *LogViewer) View() string {
-	if len(lv.entries) == 0 {
-		emptyMsg := "No debug logs yet..."
-		style := logContainerStyle
-		if lv.focused {
-			style = logFocusedStyle
-		}
-		return style.Render(timestampStyle.Render(emptyMsg))
-	}
-
-	// Calculate visible entries
-	start := lv.scrollOffset
-	end := start + lv.maxVisible
-	if end > len(lv.entries) {
-		end = len(lv.entries)
-	}
-	if start >= len(lv.entries) {
-		start = len(lv.entries) - lv.maxVisible
-		if start < 0 {
-			start = 0
-		}
-	}
-
-	var lines []string
-	for i := start; i < end; i++ {
-		entry := lv.entries[i]
-		timestamp := entry.Timestamp.Format("15:04:05")
-
-		var levelStr string
-		var style lipgloss.Style
-
-		switch entry.Level {
-		case DEBUG:
-			levelStr = "DEBUG"
-			style = debugStyle
-		case INFO:
-			levelStr = "INFO "
-			style = infoStyle
-		case ERROR:
-			levelStr = "ERROR"
-			style = logErrorStyle
-		case SUCCESS:
-			levelStr = "SUCC "
-			style = logSuccessStyle
-		}
-
-		line := fmt.Sprintf("%s %s %s",
-			timestampStyle.Render(timestamp),
-			style.Render(levelStr),
-			style.Render(entry.Message))
-		lines = append(lines, line)
-	}
-
-	// Pad with empty lines if needed
-	for len(lines) < lv.maxVisible {
-		lines = append(lines, "")
-	}
-
-	content := strings.Join(lines, "\n")
-
-	containerStyle := logContainerStyle
-	if lv.focused {
-		containerStyle = logFocusedStyle
-	}
-
-	return containerStyle.Render(content)
+func (m *model) clearLogs() {
+	m.logViewer.Clear()
 }
 
 func (m *model) loadBranches() error {
-	branches, err := m.gitService.GetRecentBranches(m.count, m.includeRemote, m.authors)
+	branches, err := m.gitService.GetRecentBranches(m.count, m.includeRemote, m.authors, m.sortOrder)
 	if err != nil {
 		return err
 	}
 	m.branches = branches
+	m.applyRemoteUpdates()
+	if currentBranch, err := m.gitService.GetCurrentBranch(); err == nil {
+		m.detachedHead = currentBranch == "HEAD"
+	}
+	// Keep the predictions shown until the selected one is checked against the new state
+	m.applyPredictions()
+	m.predictionChecked = ""
+	m.loadColumnData()
 	return nil
 }
 
@@ -365,20 +346,19 @@ func (m *model) setupTable() {
 }
 
 func (m *model) loadCommitsForSelectedBranch() {
-	if len(m.branches) == 0 {
-		m.selectedCommits = []Commit{}
-		return
-	}
-
-	selectedRow := m.tableManager.GetCursor()
-	if selectedRow >= len(m.branches) {
+	branch, ok := m.selectedBranch()
+	if !ok {
 		m.selectedCommits = []Commit{}
 		return
 	}
 
-	branchName := m.branches[selectedRow].Name
+	branchName := branch.Name
 	m.logDebug("Loading commits for selected branch: %s", branchName)
 
+	// Selections only make sense for the branch they were made on
+	m.previewCursor = 0
+	m.previewPicks = make(map[string]bool)
+
 	commits, err := m.gitService.GetBranchCommits(branchName, 5) // Show last 5 commits
 	if err != nil {
 		m.logError("Failed to load commits for branch %s: %v", branchName, err)
@@ -388,6 +368,119 @@ func (m *model) loadCommitsForSelectedBranch() {
 
 	m.selectedCommits = commits
 	m.logDebug("Loaded %d commits for branch %s", len(commits), branchName)
+
+	if m.previewMode == PreviewDiff {
+		m.loadDiffForSelectedBranch()
+	}
+}
+
+func (m *model) loadDiffForSelectedBranch() {
+	branch, ok := m.selectedBranch()
+	if !ok {
+		m.diffViewer.SetDiff(nil)
+		return
+	}
+
+	branchName := branch.Name
+	m.logDebug("Loading diff against base for branch: %s", branchName)
+
+	diff, err := m.gitService.GetBranchDiff(branchName)
+	if err != nil {
+		m.logError("Failed to load diff for branch %s: %v", branchName, err)
+		m.diffViewer.SetDiff(nil)
+		return
+	}
+
+	m.diffViewer.SetDiff(diff)
+	m.logDebug("Loaded diff for branch %s against %s", branchName, diff.Base)
+}
+
+// togglePreviewMode switches the preview pane between recent commits and the diff against base
+func (m *model) togglePreviewMode() {
+	if m.previewMode == PreviewDiff {
+		m.previewMode = PreviewCommits
+		m.diffViewer.focused = false
+		m.logDebug("Preview mode: recent commits")
+		return
+	}
+
+	m.previewMode = PreviewDiff
+	m.previewFocused = false
+	m.loadDiffForSelectedBranch()
+	m.logDebug("Preview mode: diff against base")
+}
+
+// cycleFocus moves focus from the table to the preview pane, then the logs, then back
+func (m *model) cycleFocus() {
+	switch {
+	case (m.diffViewer.focused || m.previewFocused) && m.logsVisible():
+		m.diffViewer.focused = false
+		m.previewFocused = false
+		m.logViewer.focused = true
+		m.logDebug("Switched focus to logs (use ↑↓ to scroll)")
+	case m.logViewer.focused || m.diffViewer.focused || m.previewFocused:
+		m.logViewer.focused = false
+		m.diffViewer.focused = false
+		m.previewFocused = false
+		m.logDebug("Switched focus to table")
+	case !m.previewVisible() && m.logsVisible():
+		m.logViewer.focused = true
+		m.logDebug("Switched focus to logs (use ↑↓ to scroll)")
+	case !m.previewVisible():
+		m.logDebug("Preview and logs are hidden on this terminal size")
+	case m.previewMode == PreviewDiff:
+		m.diffViewer.focused = true
+		m.logDebug("Switched focus to diff (use ↑↓/pgup/pgdown to scroll)")
+	default:
+		m.previewFocused = true
+		m.logDebug("Switched focus to commits (space to select, p to cherry-pick)")
+	}
+}
+
+// activateSelectedRow expands or collapses the group row under the cursor in tree mode,
+// and otherwise switches to the selected branch
+func (m *model) activateSelectedRow() {
+	if m.tableManager.ToggleSelectedGroup() {
+		return
+	}
+	if m.worktreeBusy("switch branches") {
+		return
+	}
+
+	// Get selected branch and switch to it
+	if len(m.branches) > 0 {
+		if branch, ok := m.selectedBranch(); ok {
+			branchName := branch.Name
+			m.logInfo("User selected branch: %s", branchName)
+			if err := m.switchToBranch(branchName); err != nil {
+				m.logError("Error in switchToBranch: %v", err)
+				m.message = fmt.Sprintf("Error: %v", err)
+			} else {
+				// Only set success message if no modal was shown
+				if !m.commitModal.IsVisible() {
+					m.message = fmt.Sprintf("Switched to branch: %s", branchName)
+					// Refresh branches after switching - this will move the selected branch to top
+					m.logDebug("Refreshing branch list after switch")
+					if err := m.loadBranches(); err != nil {
+						m.logError("Failed to refresh branches: %v", err)
+						m.err = err
+					} else {
+						m.setupTable()
+						m.logDebug("Branch list refreshed successfully")
+					}
+				}
+			}
+		}
+	}
+}
+
+// selectedBranch returns the branch under the table cursor; group rows in tree mode have none
+func (m *model) selectedBranch() (Branch, bool) {
+	branch := m.tableManager.SelectedBranch()
+	if branch == nil {
+		return Branch{}, false
+	}
+	return *branch, true
 }
 
 func (m *model) switchToBranch(branchName string) error {
@@ -423,7 +516,7 @@ func (m *model) switchToBranch(branchName string) error {
 	if hasChanges {
 		m.logInfo("Found uncommitted changes, showing commit modal")
 		// Show modal instead of switching immediately
-		m.commitModal.Show(branchName)
+		m.commitModal.Show(branchName, m.predictions[branchName])
 		return nil
 	}
 
@@ -439,13 +532,120 @@ func (m *model) switchToBranch(branchName string) error {
 	return nil
 }
 
+// modelOptions are the command line settings a model starts with
+type modelOptions struct {
+	count         int
+	includeRemote bool
+	authors       []string
+	sortOrder     SortOrder
+	fetchInterval time.Duration
+}
+
+// newModel sets up the model with the branches of the current repository loaded and any
+// unfinished operation picked up
+func newModel(config Config, gitService *GitService, options modelOptions) (model, error) {
+	columns, _ := resolveColumns(config)
+	policy, _ := resolveCommitPolicy(config)
+
+	m := model{
+		count:           options.count,
+		includeRemote:   options.includeRemote,
+		authors:         options.authors,
+		tableManager:    NewTableManager(columns, options.sortOrder),
+		gitService:      gitService,
+		sortOrder:       options.sortOrder,
+		commitModal:     NewCommitModal(policy),
+		logViewer:       NewLogViewer(config.LogScrollback),
+		diffViewer:      NewDiffViewer(),
+		commitLog:       NewCommitLogView(),
+		tagsView:        NewTagsView(),
+		keys:            mainKeys,
+		help:            newHelpModel(),
+		screen:          &screenLayout{},
+		previewPicks:    make(map[string]bool),
+		fetchInterval:   options.fetchInterval,
+		remoteUpdates:   make(map[string]RemoteUpdate),
+		predictionCache: newPredictionCache(),
+		predictions:     make(map[string]*SwitchPrediction),
+		selectedCommits: []Commit{},
+	}
+
+	// Add initial startup logging
+	m.logInfo("Application started - Recent Branches v1.0")
+	m.logDebug("Configuration: count=%d, includeRemote=%v, authors=%v, sort=%s", m.count, m.includeRemote, m.authors, m.sortOrder)
+
+	if err := m.loadBranches(); err != nil {
+		m.logError("Failed to load branches: %v", err)
+		return m, err
+	}
+
+	m.logSuccess("Successfully loaded %d branches", len(m.branches))
+	m.refreshOperationState()
+	m.setupTable()
+	m.logDebug("Table setup complete")
+	return m, nil
+}
+
 func (m model) Init() tea.Cmd {
-	return nil
+	// Checking the signing key runs gpg or ssh-add, so it is done once and off the UI goroutine
+	cmds := []tea.Cmd{loadSigningStatus(m.gitService)}
+	if m.fetchInterval > 0 {
+		cmds = append(cmds, scheduleBackgroundFetch(m.fetchInterval))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	// Predict conflicts for whichever branch ends up selected, and for the commit modal's target
+	if next, ok := next.(model); ok {
+		selected := next.predictSelected()
+		modal := next.predictCommitModal()
+		return next, tea.Batch(cmd, selected, modal)
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
+	// Remote operations stream messages regardless of which view has input
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.applyLayout()
+		return m, nil
+	case remoteProgressMsg, remoteDoneMsg:
+		return m, m.handleRemoteMsg(msg)
+	case commitOutputMsg, commitDoneMsg:
+		return m, m.handleCommitMsg(msg)
+	case predictionMsg:
+		m.handlePredictionMsg(msg)
+		return m, nil
+	case rebaseProgressMsg, rebaseDoneMsg:
+		return m, m.handleRebaseMsg(msg)
+	case backgroundFetchTickMsg, backgroundFetchMsg:
+		return m, m.handleBackgroundFetchMsg(msg)
+	case signingStatusMsg:
+		m.commitModal.SetSigningStatus(msg.status)
+		return m, nil
+	}
+
+	// The help overlay closes on any key and otherwise opens for the view that has input
+	if _, ok := msg.(tea.MouseMsg); ok && m.showHelp {
+		return m, nil
+	}
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if m.showHelp {
+			m.showHelp = false
+			return m, nil
+		}
+		if m.opensHelp(keyMsg) {
+			m.showHelp = true
+			return m, nil
+		}
+	}
+
 	// Handle modal interactions first if modal is visible
 	if m.commitModal.IsVisible() {
 		m.logDebug("Modal is visible, processing modal input")
@@ -459,34 +659,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.logInfo("Modal action taken: %d for branch: %s", action, targetBranch)
 
 			switch action {
-			case ModalActionCommit:
-				subject, description := m.commitModal.GetCommitMessage()
-				m.logInfo("User chose to commit changes: '%s'", subject)
-				m.logDebug("Committing changes with message: %s", subject)
-
-				if err := m.gitService.CommitChanges(subject, description); err != nil {
-					m.logError("Failed to commit changes: %v", err)
-					m.message = fmt.Sprintf("Commit failed: %v", err)
-				} else {
-					m.logSuccess("Changes committed successfully")
-					// Now switch to the target branch
-					m.logDebug("Now switching to target branch: %s", targetBranch)
-					if err := m.gitService.SwitchToBranch(targetBranch); err != nil {
-						m.logError("Failed to switch to branch after commit: %v", err)
-						m.message = fmt.Sprintf("Commit succeeded but branch switch failed: %v", err)
-					} else {
-						m.logSuccess("Successfully switched to branch: %s", targetBranch)
-						m.message = fmt.Sprintf("Committed changes and switched to: %s", targetBranch)
-						// Refresh branches to show new current branch at top
-						m.logDebug("Refreshing branch list after successful switch")
-						if err := m.loadBranches(); err != nil {
-							m.logError("Failed to refresh branches: %v", err)
-						} else {
-							m.setupTable()
-							m.logDebug("Branch list refreshed successfully")
-						}
-					}
-				}
+			case ModalActionCommit, ModalActionCommitNoVerify:
+				// The modal stays open while git and its hooks run
+				return m, tea.Batch(modalCmd, m.startCommit(targetBranch, action == ModalActionCommitNoVerify))
 
 			case ModalActionStash:
 				m.logInfo("User chose to stash changes")
@@ -499,7 +674,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.logSuccess("Changes stashed successfully")
 					// Now switch to the target branch
 					m.logDebug("Now switching to target branch: %s", targetBranch)
-					if err := m.gitService.SwitchToBranch(targetBranch); err != nil {
+					if err := m.completeSwitch(targetBranch); err != nil {
 						m.logError("Failed to switch to branch after stash: %v", err)
 						m.message = fmt.Sprintf("Stash succeeded but branch switch failed: %v", err)
 					} else {
@@ -518,7 +693,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			case ModalActionCancel:
 				m.logInfo("User cancelled modal - staying on current branch")
+				if err := m.commitModal.SaveDraft(); err != nil {
+					m.logError("Failed to save commit message draft: %v", err)
+				}
 				m.message = "Branch switch cancelled"
+				m.pendingHistorySteps = 0
+				m.pendingTag = ""
 			}
 
 			// Hide modal after processing action
@@ -529,66 +709,224 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, modalCmd
 	}
 
+	// The commit log takes over input while it is open
+	if m.commitLog.IsVisible() {
+		commitLog, logCmd := m.commitLog.Update(msg)
+		m.commitLog = commitLog
+
+		if m.commitLog.GetAction() == CommitLogActionCherryPick {
+			branchName := m.commitLog.GetBranch()
+			commits := m.commitLog.GetPickedCommits()
+			m.commitLog.Hide()
+			m.cherryPickCommits(branchName, commits)
+		}
+
+		return m, logCmd
+	}
+
+	// The tags tab handles its own keys, apart from a few global ones
+	if m.activeTab == TabTags {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && !m.tagsView.IsEditing() {
+			switch {
+			case key.Matches(keyMsg, m.keys.Quit):
+				m.logInfo("User quit application")
+				m.quitting = true
+				return m, tea.Quit
+			case key.Matches(keyMsg, m.keys.BranchesTab):
+				m.activeTab = TabBranches
+				return m, nil
+			case key.Matches(keyMsg, m.keys.Refresh):
+				m.loadTags()
+				m.message = "Refreshed!"
+				return m, nil
+			case key.Matches(keyMsg, m.keys.ClearLogs):
+				m.clearLogs()
+				m.logInfo("Logs cleared")
+				return m, nil
+			case key.Matches(keyMsg, m.keys.ClearMessage):
+				m.message = ""
+				return m, nil
+			}
+		}
+
+		tagsView, tagsCmd := m.tagsView.Update(msg)
+		m.tagsView = tagsView
+		m.handleTagAction()
+		return m, tagsCmd
+	}
+
+	// The focused log pane handles its own keys first
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.logViewer.focused {
+		if handled, logCmd := m.logViewer.Update(keyMsg); handled {
+			m.handleLogAction()
+			return m, logCmd
+		}
+	}
+
 	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		m.handleMouse(msg)
+		return m, nil
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
+		switch {
+		case key.Matches(msg, m.keys.Quit):
 			m.logInfo("User quit application")
 			m.quitting = true
 			return m, tea.Quit
-		case "tab":
-			// Toggle focus between table and logs
-			m.logViewer.ToggleFocus()
-			if m.logViewer.focused {
-				m.logDebug("Switched focus to logs (use ↑↓ to scroll)")
-			} else {
-				m.logDebug("Switched focus to table")
+		case key.Matches(msg, m.keys.CycleFocus):
+			// Cycle focus between table, diff preview and logs
+			m.cycleFocus()
+			return m, nil
+		case key.Matches(msg, m.keys.ToggleDiff):
+			// Toggle between commit and diff preview
+			m.togglePreviewMode()
+			return m, nil
+		case key.Matches(msg, m.keys.CommitLog):
+			// Open the full commit log for the selected branch
+			if branch, ok := m.selectedBranch(); ok {
+				m.logInfo("Opening commit log for branch: %s", branch.Name)
+				m.commitLog.Show(branch.Name)
 			}
 			return m, nil
-		case "l":
+		case key.Matches(msg, m.keys.TagsTab):
+			// Switch to the tags tab
+			m.activeTab = TabTags
+			m.loadTags()
+			return m, nil
+		case key.Matches(msg, m.keys.CycleSort):
+			// Cycle the sort key
+			m.changeSortOrder(m.sortOrder.Next())
+			return m, nil
+		case key.Matches(msg, m.keys.InvertSort):
+			// Invert the sort direction
+			m.changeSortOrder(m.sortOrder.Reversed())
+			return m, nil
+		case key.Matches(msg, m.keys.ToggleTree):
+			// Toggle the tree view grouping branches by prefix
+			m.tableManager.ToggleTreeMode()
+			m.logDebug("Tree mode: %v", m.tableManager.IsTreeMode())
+			m.loadCommitsForSelectedBranch()
+			return m, nil
+		case key.Matches(msg, m.keys.ClearLogs):
 			// Clear logs
 			m.clearLogs()
 			m.logInfo("Logs cleared")
 			return m, nil
-		case "up":
-			if m.logViewer.focused {
-				m.logViewer.ScrollUp()
+		case key.Matches(msg, m.keys.Up):
+			if m.diffViewer.focused {
+				m.diffViewer.ScrollUp()
 				return m, nil
 			}
-		case "down":
-			if m.logViewer.focused {
-				m.logViewer.ScrollDown()
+			if m.previewFocused {
+				if m.previewCursor > 0 {
+					m.previewCursor--
+				}
 				return m, nil
 			}
-		case "enter":
-			// Get selected branch and switch to it
-			if len(m.branches) > 0 {
-				selectedRow := m.tableManager.GetCursor()
-				if selectedRow < len(m.branches) {
-					branchName := m.branches[selectedRow].Name
-					m.logInfo("User selected branch: %s", branchName)
-					if err := m.switchToBranch(branchName); err != nil {
-						m.logError("Error in switchToBranch: %v", err)
-						m.message = fmt.Sprintf("Error: %v", err)
-					} else {
-						// Only set success message if no modal was shown
-						if !m.commitModal.IsVisible() {
-							m.message = fmt.Sprintf("Switched to branch: %s", branchName)
-							// Refresh branches after switching - this will move the selected branch to top
-							m.logDebug("Refreshing branch list after switch")
-							if err := m.loadBranches(); err != nil {
-								m.logError("Failed to refresh branches: %v", err)
-								m.err = err
-							} else {
-								m.setupTable()
-								m.logDebug("Branch list refreshed successfully")
-							}
-						}
-					}
+		case key.Matches(msg, m.keys.Down):
+			if m.diffViewer.focused {
+				m.diffViewer.ScrollDown()
+				return m, nil
+			}
+			if m.previewFocused {
+				if m.previewCursor < len(m.selectedCommits)-1 {
+					m.previewCursor++
 				}
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.TogglePick):
+			// Toggle cherry-pick selection of the commit under the preview cursor
+			if m.previewFocused && m.previewCursor < len(m.selectedCommits) {
+				hash := m.selectedCommits[m.previewCursor].FullHash
+				m.previewPicks[hash] = !m.previewPicks[hash]
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.CherryPick):
+			// Cherry-pick the selected preview commits onto HEAD
+			if m.previewFocused {
+				if branch, ok := m.selectedBranch(); ok {
+					m.cherryPickCommits(branch.Name, m.pickedPreviewCommits())
+				}
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Continue):
+			if m.operation != OperationNone {
+				m.continueOperation()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Skip):
+			if m.operation != OperationNone {
+				m.skipOperation()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Abort):
+			if m.operation != OperationNone {
+				m.abortOperation()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Rebase):
+			// Rebase the selected branch onto its detected base
+			if branch, ok := m.selectedBranch(); ok {
+				return m, m.rebaseBranch(branch, "")
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.RebaseOnto):
+			// Rebase onto a chosen branch: first press picks the branch, second picks the target
+			branch, ok := m.selectedBranch()
+			if !ok {
+				return m, nil
+			}
+			if m.rebaseSource == nil {
+				m.rebaseSource = &branch
+				m.message = fmt.Sprintf("Select the branch to rebase %s onto and press %s (%s: cancel)",
+					branch.Name, m.keys.RebaseOnto.Help().Key, m.keys.Cancel.Help().Key)
+				return m, nil
+			}
+			source := *m.rebaseSource
+			m.rebaseSource = nil
+			return m, m.rebaseBranch(source, gitRefForBranch(branch.Name))
+		case key.Matches(msg, m.keys.UndoRebase):
+			// Undo the last rebase of the selected branch
+			if branch, ok := m.selectedBranch(); ok {
+				m.undoRebase(branch)
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.HistoryBack):
+			// Go back through the switch history
+			m.navigateHistory(-1)
+			return m, nil
+		case key.Matches(msg, m.keys.HistoryForward):
+			// Go forward through the switch history
+			m.navigateHistory(1)
+			return m, nil
+		case key.Matches(msg, m.keys.Push):
+			return m, m.startRemoteOperation(RemotePush)
+		case key.Matches(msg, m.keys.Fetch):
+			return m, m.startRemoteOperation(RemoteFetch)
+		case key.Matches(msg, m.keys.Pull):
+			return m, m.startRemoteOperation(RemotePull)
+		case key.Matches(msg, m.keys.PullRebase):
+			return m, m.startRemoteOperation(RemotePullRebase)
+		case key.Matches(msg, m.keys.Cancel):
+			if m.rebaseSource != nil {
+				m.rebaseSource = nil
+				m.message = "Rebase cancelled"
+				return m, nil
 			}
+		case key.Matches(msg, m.keys.PageUp):
+			if m.diffViewer.focused {
+				m.diffViewer.PageUp()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.PageDown):
+			if m.diffViewer.focused {
+				m.diffViewer.PageDown()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Switch):
+			m.activateSelectedRow()
 			return m, nil
-		case "r":
+		case key.Matches(msg, m.keys.Refresh):
 			// Refresh branches
 			m.logInfo("User requested branch refresh")
 			m.message = "Refreshing..."
@@ -601,19 +939,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.logSuccess("Branch list refreshed successfully")
 			}
 			return m, nil
-		case "c":
-			// Clear message
+		case key.Matches(msg, m.keys.ClearMessage):
+			// Clear message and remote update highlights
 			m.message = ""
+			if len(m.remoteUpdates) > 0 {
+				m.remoteUpdates = make(map[string]RemoteUpdate)
+				for i := range m.branches {
+					m.branches[i].UpdatedBy = nil
+				}
+				m.tableManager.SetupTable(m.branches)
+			}
 			return m, nil
 		}
 	}
 
-	// Update table only if logs are not focused
-	if !m.logViewer.focused {
+	// Update table only if the logs and preview pane are not focused
+	if !m.logViewer.focused && !m.diffViewer.focused && !m.previewFocused {
 		oldCursor := m.tableManager.GetCursor()
-		table, tableCmd := m.tableManager.UpdateTable(msg)
-		m.tableManager.table = table
-		cmd = tableCmd
+		m.tableManager.Update(msg)
 
 		// Check if cursor position changed to load commits for new selection
 		newCursor := m.tableManager.GetCursor()
@@ -634,58 +977,101 @@ func (m model) View() string {
 		return errorStyle.Render(fmt.Sprintf("Error: %v", m.err))
 	}
 
+	if m.showHelp {
+		return m.renderHelpOverlay()
+	}
+
+	if m.logViewer.detail {
+		return m.logViewer.DetailView(m.width, m.height)
+	}
+
 	var titleText string
 	if len(m.authors) > 0 && m.authors[0] != "all" {
 		authorText := strings.Join(m.authors, ", ")
 		if authorText == "mine" {
 			authorText = "my"
 		}
-		titleText = fmt.Sprintf("Recent Git Branches (%s, %s branches)", authorText, getRemoteText(m.includeRemote))
+		titleText = fmt.Sprintf("Recent Git Branches (%s, %s branches, by %s)", authorText, getRemoteText(m.includeRemote), m.sortOrder.Label())
 	} else {
-		titleText = fmt.Sprintf("Recent Git Branches (%s)", getRemoteText(m.includeRemote))
+		titleText = fmt.Sprintf("Recent Git Branches (%s, by %s)", getRemoteText(m.includeRemote), m.sortOrder.Label())
 	}
 
-	title := titleStyle.Render(titleText)
-
-	// Commit preview section
-	commitPreview := m.renderCommitPreview()
+	title := lipgloss.JoinVertical(lipgloss.Left, titleStyle.Render(titleText), renderTabs(m.activeTab))
 
-	// Log section title with focus indicator
-	var logTitle string
-	if m.logViewer.focused {
-		logTitle = logTitleStyle.Render("Debug Logs: [FOCUSED - ↑↓ to scroll]")
+	// Commit or diff preview section
+	var commitPreview string
+	if m.previewMode == PreviewDiff {
+		commitPreview = m.diffViewer.View()
 	} else {
-		logTitle = logTitleStyle.Render("Debug Logs:")
+		commitPreview = m.renderCommitPreview()
 	}
 
-	// Help text with new shortcuts
-	help := helpStyle.Render("↑/↓: navigate/scroll • enter: switch • tab: focus logs • l: clear logs • r: refresh • q: quit")
+	// Log section title with focus indicator, filters and search
+	logTitle := m.logViewer.Title()
+
+	// Short help for whatever has focus, the full list is behind the help key
+	m.help.Width = m.width
+	help := m.help.ShortHelpView(m.activeHelp().ShortHelp())
 
 	var messageView string
 	if m.message != "" {
 		messageView = successStyle.Render(m.message)
 	}
 
-	content := lipgloss.JoinVertical(
-		lipgloss.Left,
-		title,
-		"",
-		m.tableManager.View(),
-		"",
-		commitPreview,
-		"",
-		logTitle,
-		m.logViewer.View(),
-		"",
-		messageView,
-		help,
-	)
+	// Keep unfinished operations visible until they are continued or aborted
+	if m.operation != OperationNone {
+		messageView = lipgloss.JoinVertical(lipgloss.Left, messageView, m.renderOperationBanner())
+	}
+
+	if m.detachedHead {
+		messageView = lipgloss.JoinVertical(lipgloss.Left, messageView,
+			detachedWarningStyle.Render("⚠ HEAD is detached: new commits won't belong to any branch (tags tab, b: create a branch)"))
+	}
+
+	if m.activeTab == TabTags {
+		above := []string{title, ""}
+		below := []string{""}
+		if m.logsVisible() {
+			below = append(below, logTitle, m.logViewer.View(), "")
+		}
+		below = append(below, messageView, help)
+
+		m.tagsView.SetHeight(m.tableHeightFor(above, below))
+		content := lipgloss.JoinVertical(lipgloss.Left, append(append(above, m.tagsView.View()), below...)...)
+		if m.commitModal.IsVisible() {
+			return m.commitModal.ViewOverlay(content)
+		}
+		return content
+	}
+
+	// Collapse the preview and log panes on small terminals and give the rest to the table
+	above := []string{title, ""}
+	below := []string{""}
+	previewAt, logsAt := -1, -1
+	if m.previewVisible() {
+		previewAt = len(below)
+		below = append(below, commitPreview, "")
+	}
+	if m.logsVisible() {
+		logsAt = len(below) + 1
+		below = append(below, logTitle, m.logViewer.View(), "")
+	}
+	below = append(below, messageView, help)
+
+	m.tableManager.SetHeight(m.tableHeightFor(above, below))
+	tableView := m.tableManager.View()
+	m.screen.record(above, tableView, below, previewAt, logsAt)
+	content := lipgloss.JoinVertical(lipgloss.Left, append(append(above, tableView), below...)...)
 
 	// Show modal overlay if modal is visible
 	if m.commitModal.IsVisible() {
 		return m.commitModal.ViewOverlay(content)
 	}
 
+	if m.commitLog.IsVisible() {
+		return m.commitLog.View()
+	}
+
 	return content
 }
 
@@ -694,12 +1080,12 @@ func (m model) renderCommitPreview() string {
 		return commitContainerStyle.Render("No branches available")
 	}
 
-	selectedRow := m.tableManager.GetCursor()
-	if selectedRow >= len(m.branches) {
+	branch, ok := m.selectedBranch()
+	if !ok {
 		return commitContainerStyle.Render("No branch selected")
 	}
 
-	branchName := m.branches[selectedRow].Name
+	branchName := branch.Name
 	commitTitle := commitTitleStyle.Render(fmt.Sprintf("Recent Commits - %s:", branchName))
 
 	if len(m.selectedCommits) == 0 {
@@ -711,12 +1097,26 @@ func (m model) renderCommitPreview() string {
 	commitLines = append(commitLines, commitTitle)
 	commitLines = append(commitLines, "")
 
-	for _, commit := range m.selectedCommits {
-		commitLine := fmt.Sprintf("%s %s %s - %s",
+	for i, commit := range m.selectedCommits {
+		commitLine := fmt.Sprintf("%s %s %s %s - %s",
+			signatureMarker(commit.Signature),
 			commitHashStyle.Render(commit.Hash),
 			commitTimeStyle.Render(commit.RelativeTime),
 			commitAuthorStyle.Render(commit.Author),
 			truncateString(commit.Subject, 50))
+
+		// Show cursor and cherry-pick selection while the preview is focused
+		if m.previewFocused {
+			mark := " "
+			if m.previewPicks[commit.FullHash] {
+				mark = "✓"
+			}
+			if i == m.previewCursor {
+				commitLine = commitLogSelectedStyle.Render(">" + mark + " " + commitLine)
+			} else {
+				commitLine = " " + mark + " " + commitLine
+			}
+		}
 		commitLines = append(commitLines, commitLine)
 	}
 
@@ -726,6 +1126,9 @@ func (m model) renderCommitPreview() string {
 	}
 
 	content := strings.Join(commitLines, "\n")
+	if m.previewFocused {
+		return commitFocusedStyle.Render(content)
+	}
 	return commitContainerStyle.Render(content)
 }
 
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Most messages remembered per branch for recalling in the commit modal
+const maxMessageHistory = 20
+
+// savedMessage is a commit subject and description
+type savedMessage struct {
+	Subject     string `json:"subject"`
+	Description string `json:"description,omitempty"`
+}
+
+func (s savedMessage) isEmpty() bool {
+	return strings.TrimSpace(s.Subject) == "" && strings.TrimSpace(s.Description) == ""
+}
+
+// commitMessages is what the commit modal remembers per branch: messages committed from it,
+// newest last, and a draft left when it was cancelled
+type commitMessages struct {
+	History map[string][]savedMessage `json:"history"`
+	Drafts  map[string]savedMessage   `json:"drafts"`
+}
+
+func (g *GitService) loadCommitMessages() (*commitMessages, string, error) {
+	path, err := g.repoStatePath("messages.json")
+	if err != nil {
+		return nil, "", err
+	}
+	messages := &commitMessages{}
+	if err := loadJSONFile(path, messages); err != nil {
+		return nil, "", err
+	}
+	if messages.History == nil {
+		messages.History = make(map[string][]savedMessage)
+	}
+	if messages.Drafts == nil {
+		messages.Drafts = make(map[string]savedMessage)
+	}
+	return messages, path, nil
+}
+
+// CommitMessageHistory returns the messages committed from the modal on branch, newest first
+func (g *GitService) CommitMessageHistory(branch string) []savedMessage {
+	messages, _, err := g.loadCommitMessages()
+	if err != nil {
+		return nil
+	}
+	history := messages.History[branch]
+	newestFirst := make([]savedMessage, len(history))
+	for i, message := range history {
+		newestFirst[len(history)-1-i] = message
+	}
+	return newestFirst
+}
+
+// CommitDraft returns the message left in the modal when a commit on branch was cancelled
+func (g *GitService) CommitDraft(branch string) (savedMessage, bool) {
+	messages, _, err := g.loadCommitMessages()
+	if err != nil {
+		return savedMessage{}, false
+	}
+	draft, ok := messages.Drafts[branch]
+	return draft, ok
+}
+
+// SaveCommitDraft remembers an unfinished message for branch; an empty one clears the draft
+func (g *GitService) SaveCommitDraft(branch string, draft savedMessage) error {
+	messages, path, err := g.loadCommitMessages()
+	if err != nil {
+		return err
+	}
+	if draft.isEmpty() {
+		if _, ok := messages.Drafts[branch]; !ok {
+			return nil
+		}
+		delete(messages.Drafts, branch)
+	} else {
+		messages.Drafts[branch] = draft
+	}
+	return saveJSONFile(path, messages)
+}
+
+// RecordCommitMessage adds a committed message to the history of branch and drops its draft
+func (g *GitService) RecordCommitMessage(branch string, message savedMessage) error {
+	messages, path, err := g.loadCommitMessages()
+	if err != nil {
+		return err
+	}
+
+	var history []savedMessage
+	for _, previous := range messages.History[branch] {
+		if previous != message {
+			history = append(history, previous)
+		}
+	}
+	history = append(history, message)
+	if len(history) > maxMessageHistory {
+		history = history[len(history)-maxMessageHistory:]
+	}
+	messages.History[branch] = history
+	delete(messages.Drafts, branch)
+	return saveJSONFile(path, messages)
+}
+
+// CommitTemplate returns the text of the repository's commit template, kept in
+// .git/recent-branches/commit-template, or else of git's commit.template. It is empty
+// when neither exists.
+func (g *GitService) CommitTemplate() string {
+	if path, err := g.repoStatePath("commit-template"); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			return string(data)
+		}
+	}
+
+	// --path expands ~; relative paths are relative to the top of the work tree, as for git commit
+	output, err := gitCommand("config", "--path", "commit.template").Output()
+	if err != nil {
+		return ""
+	}
+	path := strings.TrimSpace(string(output))
+	if path == "" {
+		return ""
+	}
+	if !filepath.IsAbs(path) {
+		if top, err := gitCommand("rev-parse", "--show-toplevel").Output(); err == nil {
+			path = filepath.Join(strings.TrimSpace(string(top)), path)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// parseCommitTemplate fills in the {branch} and {ticket} placeholders of a template and
+// splits it into a subject and description, dropping # comment lines as git does
+func parseCommitTemplate(template, branch, ticket string) savedMessage {
+	template = strings.NewReplacer("{branch}", branch, "{ticket}", ticket).Replace(template)
+
+	var lines []string
+	for _, line := range strings.Split(template, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimSuffix(line, "\r"))
+		}
+	}
+
+	// Trailing spaces on the subject are kept so typing continues after a prefix like "ABC-12: "
+	text := strings.TrimLeft(strings.Join(lines, "\n"), " \t\n")
+	subject, description, _ := strings.Cut(text, "\n")
+	return savedMessage{
+		Subject:     subject,
+		Description: strings.TrimSpace(description),
+	}
+}
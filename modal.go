@@ -7,6 +7,7 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -16,6 +17,7 @@ type ModalAction int
 const (
 	ModalActionNone ModalAction = iota
 	ModalActionCommit
+	ModalActionCommitNoVerify // Commit skipping the pre-commit and commit-msg hooks
 	ModalActionStash
 	ModalActionCancel
 )
@@ -29,6 +31,35 @@ const (
 	FocusDescription                   // Commit description field
 )
 
+// CommitMode is how the changes are committed
+type CommitMode int
+
+const (
+	CommitNew   CommitMode = iota // A new commit with the typed message
+	CommitAmend                   // Into the last commit, rewriting its message
+	CommitFixup                   // A fixup! commit for an earlier commit on the branch
+)
+
+func (c CommitMode) String() string {
+	switch c {
+	case CommitAmend:
+		return "Amend last commit"
+	case CommitFixup:
+		return "Fixup into..."
+	default:
+		return "New commit"
+	}
+}
+
+// Lines of commit hook output shown at once; more can be scrolled
+const maxOutputLines = 6
+
+// Commits offered to fix up, and how many are shown at once
+const (
+	maxFixupTargets = 50
+	fixupPickerRows = 5
+)
+
 type CommitModal struct {
 	visible       bool
 	subject       textinput.Model
@@ -39,20 +70,58 @@ type CommitModal struct {
 	gitStatus     []GitFileStatus
 	expandedFiles map[string]bool
 	selectedFile  int // Index of currently selected file
+	prediction    *SwitchPrediction
+	predicting    bool // Prediction was requested in the background
 	gitService    *GitService
+	policy        *CommitPolicy
+	branch        string // Branch the commit goes on, for the ticket rule and message history
+	violations    []PolicyViolation
+	prefill       savedMessage   // Message from the template, not worth keeping as a draft
+	history       []savedMessage // Messages committed on the branch, newest first
+	historyIndex  int            // Recalled history entry, -1 while editing a new message
+	historyDraft  savedMessage   // What was typed before recalling history
+	mode          CommitMode
+	typedMessage  savedMessage // Message of the new commit, put back when leaving amend
+	fixupTargets  []Commit     // The branch's own commits, newest first
+	fixupIndex    int
+	autosquash    bool // Squash the fixup into its target once committed
+	signing       SigningStatus
+	signingLoaded bool
+	sign          bool   // Sign this commit, starting from commit.gpgsign
+	warned        bool   // Commit was pressed once with only warnings; pressing again commits
+	notice        string // Why the last commit attempt didn't go through
+	committing    bool   // git commit is running; its output streams into output
+	failed        bool   // The last commit failed; it can be retried or made without hooks
+	output        viewport.Model
+	outputLines   []string
+	width         int // Terminal size, 0 until known
+	height        int
+
+	// Where View last drew the file list and buttons, for mouse clicks
+	originX, originY int         // Screen position of the modal content
+	fileLines        map[int]int // Content line of each file row
+	buttonsLine      int
+	buttons          []modalButton
 
 	// Key bindings
 	keys CommitModalKeyMap
 }
 
 type CommitModalKeyMap struct {
-	Tab      key.Binding
-	ShiftTab key.Binding
-	Commit   key.Binding
-	Stash    key.Binding
-	Cancel   key.Binding
-	Up       key.Binding
-	Down     key.Binding
+	Tab        key.Binding
+	ShiftTab   key.Binding
+	Commit     key.Binding
+	Stash      key.Binding
+	Cancel     key.Binding
+	Up         key.Binding
+	Down       key.Binding
+	Expand     key.Binding
+	NoVerify   key.Binding
+	ScrollUp   key.Binding
+	ScrollDown key.Binding
+	Mode       key.Binding
+	Sign       key.Binding
+	Help       key.Binding
 }
 
 var commitModalKeys = CommitModalKeyMap{
@@ -84,59 +153,80 @@ var commitModalKeys = CommitModalKeyMap{
 		key.WithKeys("down"),
 		key.WithHelp("↓", "down"),
 	),
+	Expand: key.NewBinding(
+		key.WithKeys(" ", "enter"),
+		key.WithHelp("space/enter", "expand file"),
+	),
+	NoVerify: key.NewBinding(
+		key.WithKeys("ctrl+o"),
+		key.WithHelp("ctrl+o", "commit --no-verify"),
+	),
+	ScrollUp: key.NewBinding(
+		key.WithKeys("pgup"),
+		key.WithHelp("pgup", "scroll output up"),
+	),
+	ScrollDown: key.NewBinding(
+		key.WithKeys("pgdown"),
+		key.WithHelp("pgdown", "scroll output down"),
+	),
+	Mode: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "new/amend/fixup"),
+	),
+	Sign: key.NewBinding(
+		key.WithKeys("ctrl+g"),
+		key.WithHelp("ctrl+g", "toggle signing"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?", "f1"),
+		key.WithHelp("?/f1", "help"),
+	),
 }
 
-// Add new key binding for expanding files
-var expandKey = key.NewBinding(
-	key.WithKeys("space", "enter"),
-	key.WithHelp("space/enter", "expand file"),
-)
-
 var (
 	modalStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("39")).
 			Padding(1, 2).
 			Width(70).
 			Height(20)
 
 	modalTitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205")).
 			Bold(true).
 			Padding(0, 0, 1, 0)
 
 	labelStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
 			Bold(true)
 
 	buttonStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("15")).
-			Background(lipgloss.Color("39")).
 			Padding(0, 2).
 			Margin(0, 1)
 
 	buttonActiveStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("15")).
-				Background(lipgloss.Color("205")).
 				Padding(0, 2).
 				Margin(0, 1).
 				Bold(true)
 
 	modalHelpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
 			Italic(true).
 			Padding(1, 0, 0, 0)
 
+	hookOutputStyle = lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder()).
+			Padding(0, 1)
+
 	overlayStyle = lipgloss.NewStyle().
 			Background(lipgloss.Color("0")).
 			Foreground(lipgloss.Color("0"))
 )
 
-func NewCommitModal() *CommitModal {
+func NewCommitModal(policy *CommitPolicy) *CommitModal {
 	subject := textinput.New()
 	subject.Placeholder = "Brief description of changes"
 	subject.Focus()
 	subject.CharLimit = 72
+	if policy != nil && policy.SubjectMaxLength >= subject.CharLimit {
+		subject.CharLimit = policy.SubjectMaxLength + 20 // Room to go over the limit and see why
+	}
 	subject.Width = 60
 
 	description := textarea.New()
@@ -147,22 +237,48 @@ func NewCommitModal() *CommitModal {
 	return &CommitModal{
 		subject:       subject,
 		description:   description,
+		output:        viewport.New(56, 6),
 		keys:          commitModalKeys,
 		focusIndex:    FocusGitStatus,
 		expandedFiles: make(map[string]bool),
 		gitService:    NewGitService(),
+		policy:        policy,
+	}
+}
+
+// SetSize scales the modal and its inputs to the terminal
+func (m *CommitModal) SetSize(width, height int) {
+	m.width, m.height = width, height
+	if width > 0 {
+		inputWidth := min(60, max(width-16, 20))
+		m.subject.Width = inputWidth
+		m.description.SetWidth(inputWidth)
+		m.output.Width = inputWidth - 4 // Output border and padding
 	}
 }
 
-func (m *CommitModal) Show(targetBranch string) {
+// Show opens the modal for a switch to targetBranch, with the conflict prediction already made
+// for it. A nil prediction is made in the background and filled in with SetPrediction.
+func (m *CommitModal) Show(targetBranch string, prediction *SwitchPrediction) {
 	m.visible = true
 	m.targetBranch = targetBranch
 	m.action = ModalActionNone
 	m.focusIndex = FocusGitStatus // Start focused on git status section
 	m.subject.Blur()              // Start with subject unfocused
 	m.description.Blur()
-	m.subject.SetValue("")
-	m.description.SetValue("")
+	m.committing = false
+	m.failed = false
+	m.mode = CommitNew
+	m.fixupTargets = nil
+	m.fixupIndex = 0
+	m.autosquash = false
+	// The status is checked once in the background, unless the modal opens before that's done
+	if !m.signingLoaded {
+		m.SetSigningStatus(m.gitService.GetSigningStatus())
+	}
+	m.sign = m.signing.Enabled
+	m.outputLines = nil
+	m.output.SetContent("")
 
 	// Load git status
 	if status, err := m.gitService.GetGitStatus(); err == nil {
@@ -174,6 +290,32 @@ func (m *CommitModal) Show(targetBranch string) {
 	// Reset expanded files and selected file
 	m.expandedFiles = make(map[string]bool)
 	m.selectedFile = 0
+
+	m.branch, _ = m.gitService.GetCurrentBranch()
+	m.loadMessage()
+	m.checkPolicy()
+
+	// Warn about conflicts before the user picks commit or stash
+	m.prediction = prediction
+	m.predicting = false
+}
+
+// SetSigningStatus keeps the signing status for the rest of the session
+func (m *CommitModal) SetSigningStatus(status SigningStatus) {
+	m.signing = status
+	m.signingLoaded = true
+}
+
+// NeedsPrediction reports whether the modal is open without a prediction and none was requested
+func (m *CommitModal) NeedsPrediction() bool {
+	return m.visible && m.prediction == nil && !m.predicting
+}
+
+// SetPrediction fills in a prediction made in the background, if it is for the current target
+func (m *CommitModal) SetPrediction(targetBranch string, prediction *SwitchPrediction) {
+	if m.visible && m.targetBranch == targetBranch {
+		m.prediction = prediction
+	}
 }
 
 func (m *CommitModal) Hide() {
@@ -199,6 +341,29 @@ func (m *CommitModal) GetTargetBranch() string {
 	return m.targetBranch
 }
 
+// GetCommitOptions returns how to commit for the chosen mode
+func (m *CommitModal) GetCommitOptions(noVerify bool) CommitOptions {
+	options := CommitOptions{Amend: m.mode == CommitAmend, NoVerify: noVerify, Sign: m.sign}
+	if target, ok := m.fixupTarget(); ok {
+		options.FixupOf = target.FullHash
+	}
+	return options
+}
+
+// GetAutosquashTarget returns the commit to squash the fixup into after committing, if chosen
+func (m *CommitModal) GetAutosquashTarget() (Commit, bool) {
+	target, ok := m.fixupTarget()
+	return target, ok && m.autosquash
+}
+
+// fixupTarget returns the commit picked to fix up while in fixup mode
+func (m *CommitModal) fixupTarget() (Commit, bool) {
+	if m.mode != CommitFixup || m.fixupIndex >= len(m.fixupTargets) {
+		return Commit{}, false
+	}
+	return m.fixupTargets[m.fixupIndex], true
+}
+
 func (m *CommitModal) Update(msg tea.Msg) (*CommitModal, tea.Cmd) {
 	if !m.visible {
 		return m, nil
@@ -207,7 +372,20 @@ func (m *CommitModal) Update(msg tea.Msg) (*CommitModal, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
+	// Only the output can be scrolled while git commit runs
+	if m.committing {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			m.scrollOutput(keyMsg)
+		}
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			m.click(msg.X-m.originX, msg.Y-m.originY)
+		}
+
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, m.keys.Cancel):
@@ -215,15 +393,29 @@ func (m *CommitModal) Update(msg tea.Msg) (*CommitModal, tea.Cmd) {
 			return m, nil
 
 		case key.Matches(msg, m.keys.Commit):
-			if strings.TrimSpace(m.subject.Value()) != "" {
-				m.action = ModalActionCommit
-				return m, nil
-			}
+			m.commit(ModalActionCommit)
+			return m, nil
+
+		case key.Matches(msg, m.keys.NoVerify) && m.failed:
+			m.commit(ModalActionCommitNoVerify)
+			return m, nil
+
+		case key.Matches(msg, m.keys.ScrollUp, m.keys.ScrollDown):
+			m.scrollOutput(msg)
 
 		case key.Matches(msg, m.keys.Stash):
 			m.action = ModalActionStash
 			return m, nil
 
+		case key.Matches(msg, m.keys.Mode):
+			m.cycleMode()
+
+		case key.Matches(msg, m.keys.Sign):
+			m.sign = !m.sign
+
+		case key.Matches(msg, m.keys.Expand) && m.inFixupPicker():
+			m.autosquash = !m.autosquash
+
 		case key.Matches(msg, m.keys.Tab):
 			m.nextField()
 
@@ -235,6 +427,10 @@ func (m *CommitModal) Update(msg tea.Msg) (*CommitModal, tea.Cmd) {
 				if len(m.gitStatus) > 0 && m.selectedFile > 0 {
 					m.selectedFile--
 				}
+			} else if m.inFixupPicker() {
+				m.fixupIndex = max(m.fixupIndex-1, 0)
+			} else if m.focusIndex == FocusSubject {
+				m.recallMessage(1)
 			} else if m.focusIndex == FocusDescription { // If in description, move to subject
 				m.focusIndex = FocusSubject
 				m.subject.Focus()
@@ -246,21 +442,28 @@ func (m *CommitModal) Update(msg tea.Msg) (*CommitModal, tea.Cmd) {
 				if len(m.gitStatus) > 0 && m.selectedFile < len(m.gitStatus)-1 {
 					m.selectedFile++
 				}
+			} else if m.inFixupPicker() {
+				m.fixupIndex = min(m.fixupIndex+1, max(len(m.fixupTargets)-1, 0))
+			} else if m.focusIndex == FocusSubject && m.historyIndex >= 0 {
+				m.recallMessage(-1)
 			} else if m.focusIndex == FocusSubject { // If in subject, move to description
 				m.focusIndex = FocusDescription
 				m.subject.Blur()
 				m.description.Focus()
 			}
 
-		case msg.String() == " " || msg.String() == "enter":
-			// Toggle expansion of selected file (only when focused on git status)
-			if m.focusIndex == FocusGitStatus && len(m.gitStatus) > 0 && m.selectedFile < len(m.gitStatus) {
+		case key.Matches(msg, m.keys.Expand) && m.focusIndex == FocusGitStatus:
+			// Toggle expansion of selected file
+			if len(m.gitStatus) > 0 && m.selectedFile < len(m.gitStatus) {
 				selectedFilePath := m.gitStatus[m.selectedFile].Path
 				m.expandedFiles[selectedFilePath] = !m.expandedFiles[selectedFilePath]
 			}
 
 		default:
-			// Handle input in the focused field
+			// Handle input in the focused field; the fixup picker has nothing to type into
+			if m.inFixupPicker() {
+				return m, nil
+			}
 			if m.focusIndex == FocusSubject { // Subject field
 				m.subject, cmd = m.subject.Update(msg)
 				cmds = append(cmds, cmd)
@@ -269,14 +472,216 @@ func (m *CommitModal) Update(msg tea.Msg) (*CommitModal, tea.Cmd) {
 				cmds = append(cmds, cmd)
 			}
 			// When focusIndex == FocusGitStatus, don't pass keys to text inputs
+			m.checkPolicy()
 		}
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
+// message returns the subject and description as typed
+func (m *CommitModal) message() savedMessage {
+	subject, description := m.GetCommitMessage()
+	return savedMessage{Subject: subject, Description: description}
+}
+
+func (m *CommitModal) setMessage(message savedMessage) {
+	m.subject.SetValue(message.Subject)
+	m.subject.CursorEnd()
+	m.description.SetValue(message.Description)
+}
+
+// loadMessage fills the fields with the draft left on the branch, or else the commit template
+func (m *CommitModal) loadMessage() {
+	m.history = nil
+	m.historyIndex = -1
+	m.prefill = savedMessage{}
+	if template := m.gitService.CommitTemplate(); template != "" {
+		m.prefill = parseCommitTemplate(template, m.branch, m.policy.ticket(m.branch))
+	}
+
+	message := m.prefill
+	if m.hasBranch() {
+		m.history = m.gitService.CommitMessageHistory(m.branch)
+		if draft, ok := m.gitService.CommitDraft(m.branch); ok {
+			message = draft
+		}
+	}
+	m.setMessage(message)
+}
+
+// hasBranch reports whether HEAD is on a branch that messages can be remembered for
+func (m *CommitModal) hasBranch() bool {
+	return m.branch != "" && m.branch != "HEAD"
+}
+
+// recallMessage steps through the branch's previous messages, older for 1 and newer for -1,
+// returning to what was typed after the newest
+func (m *CommitModal) recallMessage(step int) {
+	index := m.historyIndex + step
+	if index >= len(m.history) || index < -1 {
+		return
+	}
+	if m.historyIndex == -1 {
+		m.historyDraft = m.message()
+	}
+
+	m.historyIndex = index
+	if index == -1 {
+		m.setMessage(m.historyDraft)
+	} else {
+		m.setMessage(m.history[index])
+	}
+	m.checkPolicy()
+}
+
+// inFixupPicker reports whether the fixup picker stands in for the message fields and has focus
+func (m *CommitModal) inFixupPicker() bool {
+	return m.mode == CommitFixup && m.focusIndex != FocusGitStatus
+}
+
+// cycleMode moves on to the next way of committing. Amending fills in the last commit's
+// message, and the message typed for a new commit comes back when amending is left.
+func (m *CommitModal) cycleMode() {
+	switch m.mode {
+	case CommitNew:
+		subject, description, err := m.gitService.GetLastCommitMessage()
+		if err != nil {
+			m.notice = "There is no commit to amend"
+			return
+		}
+		m.typedMessage = m.message()
+		m.historyIndex = -1
+		m.setMessage(savedMessage{Subject: subject, Description: description})
+		m.mode = CommitAmend
+
+	case CommitAmend:
+		m.setMessage(m.typedMessage)
+		m.mode = CommitFixup
+		m.fixupIndex = 0
+		m.fixupTargets = nil
+		if m.hasBranch() {
+			m.fixupTargets, _ = m.gitService.GetBranchLog(m.branch, 0, maxFixupTargets, true)
+		}
+		// The picker takes the place of both message fields
+		if m.focusIndex == FocusDescription {
+			m.focusIndex = FocusSubject
+		}
+		m.updateFieldFocus()
+
+	case CommitFixup:
+		m.mode = CommitNew
+		m.updateFieldFocus()
+	}
+	m.checkPolicy()
+}
+
+// SaveDraft keeps what was typed for the next time the modal opens on the branch
+func (m *CommitModal) SaveDraft() error {
+	if !m.hasBranch() {
+		return nil
+	}
+	draft := m.message()
+	if m.mode == CommitAmend {
+		draft = m.typedMessage // The fields hold the last commit's message
+	}
+	if draft == m.prefill {
+		draft = savedMessage{}
+	}
+	return m.gitService.SaveCommitDraft(m.branch, draft)
+}
+
+// RecordCommit adds the committed message to the branch's history and drops its draft.
+// A fixup leaves the typed message as a draft, since git wrote the message.
+func (m *CommitModal) RecordCommit() error {
+	if !m.hasBranch() {
+		return nil
+	}
+	if m.mode == CommitFixup {
+		return m.SaveDraft()
+	}
+	return m.gitService.RecordCommitMessage(m.branch, m.message())
+}
+
+// checkPolicy checks the message against the commit policy as it's typed
+func (m *CommitModal) checkPolicy() {
+	subject, description := m.GetCommitMessage()
+	m.violations = nil
+	if m.mode != CommitFixup { // git writes the fixup! message
+		m.violations = m.policy.Check(subject, description, m.branch)
+	}
+	m.warned = false
+	m.notice = ""
+}
+
+// commit commits unless the subject is empty or the message breaks an error rule;
+// warnings need the commit key pressed a second time
+func (m *CommitModal) commit(action ModalAction) {
+	if m.mode == CommitFixup {
+		if _, ok := m.fixupTarget(); !ok {
+			m.notice = "There are no commits on this branch to fix up"
+			return
+		}
+	} else if strings.TrimSpace(m.subject.Value()) == "" {
+		m.focusIndex = FocusSubject
+		m.updateFieldFocus()
+		return
+	}
+
+	errors, warnings := countViolations(m.violations)
+	switch {
+	case errors > 0:
+		m.notice = "Fix the errors above to commit"
+	case warnings > 0 && !m.warned:
+		m.warned = true
+		m.notice = fmt.Sprintf("Press %s again to commit anyway", m.keys.Commit.Help().Key)
+	default:
+		m.action = action
+	}
+}
+
+// StartCommit shows git commit running and clears the output of the last attempt
+func (m *CommitModal) StartCommit() {
+	m.action = ModalActionNone
+	m.committing = true
+	m.failed = false
+	m.notice = ""
+	m.outputLines = nil
+	m.output.SetContent("")
+}
+
+// AppendOutput adds a line of git or hook output, following the end of the output
+func (m *CommitModal) AppendOutput(line string) {
+	m.outputLines = append(m.outputLines, line)
+	m.output.Height = min(len(m.outputLines), maxOutputLines)
+	m.output.SetContent(strings.Join(m.outputLines, "\n"))
+	m.output.GotoBottom()
+}
+
+// CommitFailed keeps the modal open with the message as typed so the commit can be retried
+func (m *CommitModal) CommitFailed(err error) {
+	m.committing = false
+	m.failed = true
+	reason, _, _ := strings.Cut(err.Error(), "\n")
+	m.notice = fmt.Sprintf("%s. Press %s to retry or %s to skip the hooks.",
+		reason, m.keys.Commit.Help().Key, m.keys.NoVerify.Help().Key)
+}
+
+// scrollOutput scrolls the commit output for the scroll keys
+func (m *CommitModal) scrollOutput(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, m.keys.ScrollUp):
+		m.output.HalfPageUp()
+	case key.Matches(msg, m.keys.ScrollDown):
+		m.output.HalfPageDown()
+	}
+}
+
 func (m *CommitModal) nextField() {
 	m.focusIndex = ModalFocus((int(m.focusIndex) + 1) % 3) // Cycle through all focus states
+	if m.mode == CommitFixup && m.focusIndex == FocusDescription {
+		m.focusIndex = FocusGitStatus
+	}
 	m.updateFieldFocus()
 }
 
@@ -286,10 +691,18 @@ func (m *CommitModal) prevField() {
 		newIndex = 2 // FocusDescription
 	}
 	m.focusIndex = ModalFocus(newIndex)
+	if m.mode == CommitFixup && m.focusIndex == FocusDescription {
+		m.focusIndex = FocusSubject
+	}
 	m.updateFieldFocus()
 }
 
 func (m *CommitModal) updateFieldFocus() {
+	if m.mode == CommitFixup { // Only the picker, which isn't a text field
+		m.subject.Blur()
+		m.description.Blur()
+		return
+	}
 	switch m.focusIndex {
 	case FocusGitStatus: // Git status section
 		m.subject.Blur()
@@ -310,58 +723,213 @@ func (m *CommitModal) View() string {
 
 	title := modalTitleStyle.Render(fmt.Sprintf("Uncommitted Changes - Switching to '%s'", m.targetBranch))
 
+	// Predicted conflicts go right under the title
+	for _, warning := range m.prediction.Warnings(m.targetBranch) {
+		title = lipgloss.JoinVertical(lipgloss.Left, title, predictionWarningStyle.Render("⚠ "+warning))
+	}
+
 	// Git status section
 	statusSection := m.renderGitStatus()
 
-	subjectLabel := labelStyle.Render("Commit Subject:")
-	subjectInput := m.subject.View()
-
-	descLabel := labelStyle.Render("Description (optional):")
-	descInput := m.description.View()
+	// The message fields, or the commit picker when fixing up
+	var message []string
+	if m.mode == CommitFixup {
+		message = []string{m.renderFixupPicker()}
+	} else {
+		subjectLabel := "Commit Subject:"
+		if m.mode == CommitAmend {
+			subjectLabel = "Amended Subject:"
+		}
+		message = []string{
+			labelStyle.Render(subjectLabel),
+			m.subject.View(),
+			"",
+			labelStyle.Render("Description (optional):"),
+			m.description.View(),
+		}
+	}
 
 	// Buttons
-	commitBtn := buttonStyle.Render("Commit & Switch")
+	commitLabel := map[CommitMode]string{
+		CommitNew:   "Commit & Switch",
+		CommitAmend: "Amend & Switch",
+		CommitFixup: "Fixup & Switch",
+	}[m.mode]
+	commitBtn := buttonStyle.Render(commitLabel)
 	stashBtn := buttonStyle.Render("Stash & Switch")
 	cancelBtn := buttonStyle.Render("Cancel")
 
 	if m.focusIndex == FocusDescription { // If we were to add button focus
-		commitBtn = buttonActiveStyle.Render("Commit & Switch")
+		commitBtn = buttonActiveStyle.Render(commitLabel)
 	}
 
-	buttons := lipgloss.JoinHorizontal(lipgloss.Left, commitBtn, stashBtn, cancelBtn)
+	type button struct {
+		view   string
+		action ModalAction
+	}
+	shown := []button{{commitBtn, ModalActionCommit}, {stashBtn, ModalActionStash}, {cancelBtn, ModalActionCancel}}
+	// After a failed commit, offer to retry it or to make it without hooks
+	if m.failed {
+		shown = append([]button{
+			{buttonActiveStyle.Render("Retry"), ModalActionCommit},
+			{buttonStyle.Render("Skip Hooks"), ModalActionCommitNoVerify},
+		}, shown[1:]...)
+	}
 
-	help := modalHelpStyle.Render("ctrl+s: commit • ctrl+t: stash • space: expand file • esc: cancel")
+	var views []string
+	for _, b := range shown {
+		views = append(views, b.view)
+	}
+	buttons := lipgloss.JoinHorizontal(lipgloss.Left, views...)
+	m.buttons = m.buttons[:0]
+	x := 0
+	for _, button := range shown {
+		width := lipgloss.Width(button.view)
+		m.buttons = append(m.buttons, modalButton{from: x, to: x + width, action: button.action})
+		x += width
+	}
 
-	content := lipgloss.JoinVertical(
-		lipgloss.Left,
-		title,
-		"",
-		statusSection,
-		"",
-		subjectLabel,
-		subjectInput,
-		"",
-		descLabel,
-		descInput,
-		"",
-		buttons,
-		"",
-		help,
-	)
+	help := modalHelpStyle.Render(shortHelp(m.helpKeys().short...))
 
-	modal := modalStyle.Render(content)
+	sections := []string{title, "", statusSection, "", m.renderModes(), m.signing.render(m.sign) + " " + m.keys.Sign.Help().Key, ""}
+	sections = append(sections, message...)
+	aboveButtons := lipgloss.JoinVertical(lipgloss.Left, append(sections, "")...)
+	// Rule violations show once there is a subject to check
+	if len(m.violations) > 0 && strings.TrimSpace(m.subject.Value()) != "" {
+		aboveButtons = lipgloss.JoinVertical(lipgloss.Left, aboveButtons, renderViolations(m.violations), "")
+	}
+	if m.committing || len(m.outputLines) > 0 {
+		label := "Hook output:"
+		if m.committing {
+			label = "Running git commit..."
+		}
+		output := labelStyle.Render(label)
+		if len(m.outputLines) > 0 {
+			output = lipgloss.JoinVertical(lipgloss.Left, output, hookOutputStyle.Render(m.output.View()))
+		}
+		aboveButtons = lipgloss.JoinVertical(lipgloss.Left, aboveButtons, output, "")
+	}
+	if m.notice != "" {
+		aboveButtons = lipgloss.JoinVertical(lipgloss.Left, aboveButtons, labelStyle.Render(m.notice), "")
+	}
+	content := lipgloss.JoinVertical(lipgloss.Left, aboveButtons, buttons, "", help)
+
+	// Offset the file rows recorded by renderGitStatus to lines of the whole content
+	statusTop := lipgloss.Height(lipgloss.JoinVertical(lipgloss.Left, title, ""))
+	fileLines := make(map[int]int, len(m.fileLines))
+	for line, file := range m.fileLines {
+		fileLines[statusTop+line] = file
+	}
+	m.fileLines = fileLines
+	m.buttonsLine = lipgloss.Height(aboveButtons)
+
+	// Scale the modal down on small terminals and center it
+	width, height := 80, 25
+	style := modalStyle
+	if m.width > 0 && m.height > 0 {
+		width, height = m.width, m.height
+		style = style.Width(min(70, max(width-4, 30))).Height(min(20, max(height-4, 10)))
+	}
+	box := style.Render(content)
+	m.originX = max(width-lipgloss.Width(box), 0)/2 + style.GetBorderLeftSize() + style.GetPaddingLeft()
+	m.originY = max(height-lipgloss.Height(box), 0)/2 + style.GetBorderTopSize() + style.GetPaddingTop()
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
 
-	// Center the modal (this is a simple centering approach)
-	return lipgloss.Place(80, 25, lipgloss.Center, lipgloss.Center, modal)
+// renderModes shows the ways of committing with the chosen one highlighted
+func (m *CommitModal) renderModes() string {
+	var modes []string
+	for _, mode := range []CommitMode{CommitNew, CommitAmend, CommitFixup} {
+		if mode == m.mode {
+			modes = append(modes, tabActiveStyle.Render(mode.String()))
+		} else {
+			modes = append(modes, tabInactiveStyle.Render(mode.String()))
+		}
+	}
+	modes = append(modes, " "+m.keys.Mode.Help().Key)
+	return lipgloss.JoinHorizontal(lipgloss.Left, modes...)
+}
+
+// renderFixupPicker lists the branch's commits to fix up, scrolled to keep the pick in view
+func (m *CommitModal) renderFixupPicker() string {
+	focusIndicator := ""
+	if m.inFixupPicker() {
+		focusIndicator = " [FOCUSED - ↑↓ to pick]"
+	}
+	lines := []string{labelStyle.Render("Fix up commit:" + focusIndicator)}
+	if len(m.fixupTargets) == 0 {
+		lines = append(lines, "  No commits on this branch to fix up")
+		return strings.Join(lines, "\n")
+	}
+
+	start := min(max(m.fixupIndex-fixupPickerRows/2, 0), max(len(m.fixupTargets)-fixupPickerRows, 0))
+	end := min(start+fixupPickerRows, len(m.fixupTargets))
+	for i := start; i < end; i++ {
+		commit := m.fixupTargets[i]
+		indicator := " "
+		if i == m.fixupIndex {
+			indicator = ">"
+		}
+		line := fmt.Sprintf(" %s %s %s", indicator, commitHashStyle.Render(commit.Hash), commit.Subject)
+		if i == m.fixupIndex && m.inFixupPicker() {
+			line = commitLogSelectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if len(m.fixupTargets) > fixupPickerRows {
+		lines = append(lines, fmt.Sprintf("  (%d of %d)", m.fixupIndex+1, len(m.fixupTargets)))
+	}
+
+	check := "[ ]"
+	if m.autosquash {
+		check = "[x]"
+	}
+	lines = append(lines, "", fmt.Sprintf("%s Squash into it now (%s)", check, m.keys.Expand.Help().Key))
+	return strings.Join(lines, "\n")
+}
+
+// modalButton is the span of columns a button was drawn on
+type modalButton struct {
+	from, to int
+	action   ModalAction
+}
+
+// click expands a clicked file or presses a clicked button; x and y are relative to the content
+func (m *CommitModal) click(x, y int) {
+	if file, ok := m.fileLines[y]; ok {
+		m.focusIndex = FocusGitStatus
+		m.updateFieldFocus()
+		m.selectedFile = file
+		path := m.gitStatus[file].Path
+		m.expandedFiles[path] = !m.expandedFiles[path]
+		return
+	}
+
+	if y != m.buttonsLine {
+		return
+	}
+	for _, button := range m.buttons {
+		if x < button.from || x >= button.to {
+			continue
+		}
+		// Committing goes through the same checks as with the keyboard
+		if button.action == ModalActionCommit || button.action == ModalActionCommitNoVerify {
+			m.commit(button.action)
+			return
+		}
+		m.action = button.action
+		return
+	}
 }
 
 func (m *CommitModal) renderGitStatus() string {
+	m.fileLines = make(map[int]int, len(m.gitStatus))
 	if len(m.gitStatus) == 0 {
 		return labelStyle.Render("No changes detected")
 	}
 
-	filePathStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))                                   // Cyan
-	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57")) // Highlighted
+	filePathStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Primary))
+	selectedStyle := commitLogSelectedStyle // Highlighted
 
 	var lines []string
 	focusIndicator := ""
@@ -376,19 +944,19 @@ func (m *CommitModal) renderGitStatus() string {
 		switch file.Status {
 		case "A":
 			statusIcon = "+"
-			statusColor = "42" // Green
+			statusColor = theme.Success
 		case "M":
 			statusIcon = "~"
-			statusColor = "226" // Yellow
+			statusColor = theme.Highlight
 		case "D":
 			statusIcon = "-"
-			statusColor = "196" // Red
+			statusColor = theme.Error
 		case "R":
 			statusIcon = "→"
-			statusColor = "39" // Cyan
+			statusColor = theme.Primary
 		default:
 			statusIcon = "?"
-			statusColor = "241" // Gray
+			statusColor = theme.Muted
 		}
 
 		statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(statusColor)).Bold(true)
@@ -420,8 +988,8 @@ func (m *CommitModal) renderGitStatus() string {
 		// Format line count statistics
 		var lineStats string
 		if file.LinesAdded > 0 || file.LinesDeleted > 0 {
-			addedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))    // Green
-			deletedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")) // Red
+			addedStyle := diffAddStyle
+			deletedStyle := diffDelStyle
 
 			if file.LinesAdded > 0 && file.LinesDeleted > 0 {
 				lineStats = fmt.Sprintf(" (%s, %s)",
@@ -449,6 +1017,7 @@ func (m *CommitModal) renderGitStatus() string {
 			fileLine = selectedStyle.Render(fileLine)
 		}
 
+		m.fileLines[len(lines)] = i
 		lines = append(lines, fileLine)
 
 		// Show diff if expanded
@@ -462,7 +1031,7 @@ func (m *CommitModal) renderGitStatus() string {
 					diffLines = append(diffLines, "    ... (truncated)")
 				}
 
-				diffStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Italic(true)
+				diffStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Border)).Italic(true)
 				for _, diffLine := range diffLines {
 					if strings.TrimSpace(diffLine) != "" {
 						lines = append(lines, "    "+diffStyle.Render(diffLine))
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Two clicks on the same row within this interval switch to its branch
+const doubleClickInterval = 500 * time.Millisecond
+
+// screenArea is a band of lines of the screen
+type screenArea struct {
+	top    int
+	height int
+}
+
+func (a screenArea) contains(y int) bool {
+	return y >= a.top && y < a.top+a.height
+}
+
+// screenLayout is where View last drew the panes of the branches tab, for mouse hit testing
+type screenLayout struct {
+	table   screenArea
+	preview screenArea
+	logs    screenArea
+}
+
+// record measures the sections View stacks around the table. previewAt and logsAt index the
+// preview and log pane in below, or are -1 when the pane is hidden.
+func (s *screenLayout) record(above []string, table string, below []string, previewAt, logsAt int) {
+	*s = screenLayout{}
+	y := lipgloss.Height(lipgloss.JoinVertical(lipgloss.Left, above...))
+	s.table = screenArea{top: y, height: lipgloss.Height(table)}
+	y += s.table.height
+	for i, section := range below {
+		area := screenArea{top: y, height: lipgloss.Height(section)}
+		switch i {
+		case previewAt:
+			s.preview = area
+		case logsAt:
+			s.logs = area
+		}
+		y += area.height
+	}
+}
+
+// handleMouse selects rows and focuses panes on click, switches branches on double click
+// and scrolls the pane under the pointer with the wheel
+func (m *model) handleMouse(msg tea.MouseMsg) {
+	if msg.Action != tea.MouseActionPress {
+		return
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scrollAt(msg.Y, -1)
+	case tea.MouseButtonWheelDown:
+		m.scrollAt(msg.Y, 1)
+	case tea.MouseButtonLeft:
+		m.clickAt(msg.Y)
+	}
+}
+
+// scrollAt scrolls the table, diff or logs by one line, depending on which is at line y
+func (m *model) scrollAt(y, delta int) {
+	switch {
+	case m.screen.table.contains(y):
+		oldCursor := m.tableManager.GetCursor()
+		m.tableManager.MoveCursor(delta)
+		if m.tableManager.GetCursor() != oldCursor {
+			m.loadCommitsForSelectedBranch()
+		}
+
+	case m.screen.preview.contains(y) && m.previewMode == PreviewDiff:
+		if delta < 0 {
+			m.diffViewer.ScrollUp()
+		} else {
+			m.diffViewer.ScrollDown()
+		}
+
+	case m.screen.logs.contains(y):
+		if delta < 0 {
+			m.logViewer.ScrollUp()
+		} else {
+			m.logViewer.ScrollDown()
+		}
+	}
+}
+
+// clickAt focuses the pane at line y and, in the table, selects the clicked row
+func (m *model) clickAt(y int) {
+	switch {
+	case m.screen.table.contains(y):
+		row := m.tableManager.rowAt(y - m.screen.table.top - tableStyle.GetBorderTopSize())
+		if row < 0 {
+			return
+		}
+		m.logViewer.focused = false
+		m.diffViewer.focused = false
+		m.previewFocused = false
+
+		doubleClick := row == m.lastClickRow && time.Since(m.lastClickAt) < doubleClickInterval
+		m.lastClickRow, m.lastClickAt = row, time.Now()
+
+		if row != m.tableManager.GetCursor() {
+			m.tableManager.SetCursor(row)
+			m.loadCommitsForSelectedBranch()
+		}
+		if doubleClick {
+			m.lastClickAt = time.Time{}
+			m.activateSelectedRow()
+		}
+
+	case m.screen.preview.contains(y):
+		m.logViewer.focused = false
+		m.diffViewer.focused = m.previewMode == PreviewDiff
+		m.previewFocused = m.previewMode != PreviewDiff
+
+	case m.screen.logs.contains(y):
+		m.logViewer.focused = true
+		m.diffViewer.focused = false
+		m.previewFocused = false
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// GitOperation identifies a multi-step git command that can stop and wait for conflict resolution
+type GitOperation int
+
+const (
+	OperationNone GitOperation = iota
+	OperationCherryPick
+	OperationRebase
+)
+
+func (op GitOperation) String() string {
+	switch op {
+	case OperationCherryPick:
+		return "cherry-pick"
+	case OperationRebase:
+		return "rebase"
+	default:
+		return "none"
+	}
+}
+
+// command returns the git subcommand that drives the operation
+func (op GitOperation) command() string {
+	return op.String()
+}
+
+// ConflictError is returned when a git operation stops because of conflicts
+type ConflictError struct {
+	Operation GitOperation
+	Files     []string
+}
+
+func (e *ConflictError) Error() string {
+	if len(e.Files) == 0 {
+		return fmt.Sprintf("%s stopped on conflicts", e.Operation)
+	}
+	return fmt.Sprintf("%s stopped on conflicts in: %s", e.Operation, strings.Join(e.Files, ", "))
+}
+
+var (
+	operationBannerStyle = lipgloss.NewStyle().
+		Bold(true).
+		Padding(0, 1)
+)
+
+// refreshOperationState picks up operations left unfinished, including ones started outside the tool
+func (m *model) refreshOperationState() {
+	// A rebase running in the background reports its own outcome
+	if m.rebaseEvents != nil {
+		return
+	}
+	m.operation = m.gitService.GetOperationInProgress()
+	m.conflictFiles = nil
+	if m.operation == OperationNone {
+		return
+	}
+
+	files, err := m.gitService.GetConflictedFiles()
+	if err != nil {
+		m.logError("Failed to list conflicted files: %v", err)
+	}
+	m.conflictFiles = files
+	m.logInfo("A %s is in progress (%s)", m.operation, shortHelp(m.keys.Continue, m.keys.Skip, m.keys.Abort))
+}
+
+// worktreeBusy refuses an action that changes the worktree while a rebase or another operation owns it
+func (m *model) worktreeBusy(action string) bool {
+	if m.rebaseEvents != nil {
+		m.logError("Cannot %s while a rebase is running", action)
+		m.message = "Wait for the rebase to finish"
+		return true
+	}
+	if m.operation != OperationNone {
+		m.logError("Cannot %s while a %s is in progress", action, m.operation)
+		m.message = fmt.Sprintf("Finish or abort the %s first", m.operation)
+		return true
+	}
+	return false
+}
+
+// handleOperationResult records the outcome of starting or continuing an operation
+func (m *model) handleOperationResult(op GitOperation, err error) {
+	if err == nil {
+		m.operation = OperationNone
+		m.conflictFiles = nil
+		m.logSuccess("%s completed", op)
+		m.message = fmt.Sprintf("%s completed", op)
+		m.refreshAfterOperation()
+		return
+	}
+
+	var conflict *ConflictError
+	if errors.As(err, &conflict) {
+		m.operation = conflict.Operation
+		m.conflictFiles = conflict.Files
+		m.logError("%v", conflict)
+		m.message = fmt.Sprintf("Resolve conflicts, then press %s to continue, %s to skip or %s to abort",
+			m.keys.Continue.Help().Key, m.keys.Skip.Help().Key, m.keys.Abort.Help().Key)
+		return
+	}
+
+	m.logError("%v", err)
+	m.message = fmt.Sprintf("Error: %v", err)
+	m.refreshOperationState()
+}
+
+func (m *model) refreshAfterOperation() {
+	if err := m.loadBranches(); err != nil {
+		m.logError("Failed to refresh branches: %v", err)
+		return
+	}
+	m.setupTable()
+}
+
+// pickedPreviewCommits returns the selected preview commits, or the one under the cursor if none are selected
+func (m *model) pickedPreviewCommits() []Commit {
+	var picked []Commit
+	for _, commit := range m.selectedCommits {
+		if m.previewPicks[commit.FullHash] {
+			picked = append(picked, commit)
+		}
+	}
+	if len(picked) == 0 && m.previewCursor < len(m.selectedCommits) {
+		picked = append(picked, m.selectedCommits[m.previewCursor])
+	}
+	return picked
+}
+
+// cherryPickCommits applies commits from another branch onto HEAD, oldest first
+func (m *model) cherryPickCommits(branchName string, commits []Commit) {
+	if len(commits) == 0 {
+		return
+	}
+
+	if m.worktreeBusy("cherry-pick") {
+		return
+	}
+
+	currentBranch, err := m.gitService.GetCurrentBranch()
+	if err == nil && gitRefForBranch(branchName) == currentBranch {
+		m.logError("Commits from %s are already on the current branch", branchName)
+		m.message = "Select commits from a different branch to cherry-pick"
+		return
+	}
+
+	// Lists are shown newest first, git needs them in the order they were made
+	hashes := make([]string, 0, len(commits))
+	for i := len(commits) - 1; i >= 0; i-- {
+		hashes = append(hashes, commits[i].FullHash)
+		m.logInfo("Cherry-picking %s from %s: %s", commits[i].Hash, branchName, commits[i].Subject)
+	}
+
+	m.handleOperationResult(OperationCherryPick, m.gitService.CherryPick(hashes))
+	m.previewPicks = make(map[string]bool)
+}
+
+func (m *model) continueOperation() {
+	op := m.operation
+	m.logInfo("Continuing %s", op)
+	m.handleOperationResult(op, m.gitService.ContinueOperation(op))
+}
+
+func (m *model) skipOperation() {
+	op := m.operation
+	m.logInfo("Skipping current commit of %s", op)
+	m.handleOperationResult(op, m.gitService.SkipOperation(op))
+}
+
+func (m *model) abortOperation() {
+	op := m.operation
+	m.logInfo("Aborting %s", op)
+	if err := m.gitService.AbortOperation(op); err != nil {
+		m.logError("%v", err)
+		m.message = fmt.Sprintf("Error: %v", err)
+		return
+	}
+
+	m.operation = OperationNone
+	m.conflictFiles = nil
+	m.logSuccess("%s aborted", op)
+	m.message = fmt.Sprintf("%s aborted", op)
+	m.refreshAfterOperation()
+}
+
+func (m model) renderOperationBanner() string {
+	text := fmt.Sprintf("%s in progress", m.operation)
+	if len(m.conflictFiles) > 0 {
+		text = fmt.Sprintf("%s stopped on conflicts in: %s", m.operation, strings.Join(m.conflictFiles, ", "))
+	}
+	return operationBannerStyle.Render(text + " • " + shortHelp(m.keys.Continue, m.keys.Skip, m.keys.Abort))
+}
+
+// rebaseProgressMsg carries one line of output from a running rebase
+type rebaseProgressMsg struct {
+	line string
+}
+
+// rebaseDoneMsg is sent once a rebase running in the background has finished
+type rebaseDoneMsg struct {
+	branch         string  // Branch that was rebased
+	previousBranch string  // Branch checked out when the rebase started
+	fixup          *Commit // Commit a fixup from the commit modal was squashed into
+	switchTo       string  // Branch to switch to once the fixup is squashed
+	err            error
+}
+
+// rebaseBranch rebases a listed branch onto the given ref, or its detected base when onto is empty.
+// The rebase runs in the background with its output streamed into the logs.
+func (m *model) rebaseBranch(branch Branch, onto string) tea.Cmd {
+	if m.worktreeBusy("rebase") {
+		return nil
+	}
+
+	if branch.IsRemote {
+		m.logError("Cannot rebase remote branch %s", branch.Name)
+		m.message = "Only local branches can be rebased"
+		return nil
+	}
+
+	if onto == "" {
+		base, _, err := m.gitService.findBaseBranch(branch.Name)
+		if err != nil || base == "root commit" {
+			m.logError("Could not detect a base branch for %s", branch.Name)
+			m.message = fmt.Sprintf("No base branch found for %s", branch.Name)
+			return nil
+		}
+		onto = base
+	}
+
+	if onto == branch.Name {
+		m.logError("Cannot rebase %s onto itself", branch.Name)
+		m.message = fmt.Sprintf("%s is its own base", branch.Name)
+		return nil
+	}
+
+	if count, err := m.gitService.CountCommitsBetween(onto, branch.Name); err == nil {
+		m.logInfo("Rebasing %d commits of %s onto %s", count, branch.Name, onto)
+	} else {
+		m.logInfo("Rebasing %s onto %s", branch.Name, onto)
+	}
+
+	m.message = fmt.Sprintf("Rebasing %s onto %s...", branch.Name, onto)
+
+	previousBranch, _ := m.gitService.GetCurrentBranch()
+	events := make(chan tea.Msg, 64)
+	m.rebaseEvents = events
+	gitService := m.gitService
+
+	go func() {
+		defer close(events)
+		progress := func(line string) {
+			events <- rebaseProgressMsg{line: line}
+		}
+		err := gitService.RebaseBranch(branch.Name, onto, progress)
+		events <- rebaseDoneMsg{branch: branch.Name, previousBranch: previousBranch, err: err}
+	}()
+
+	return waitForEvent(events)
+}
+
+// handleRebaseMsg logs rebase output and records the outcome once it finishes
+func (m *model) handleRebaseMsg(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case rebaseProgressMsg:
+		m.logProgress(msg.line)
+		return waitForEvent(m.rebaseEvents)
+
+	case rebaseDoneMsg:
+		m.rebaseEvents = nil
+		if msg.fixup != nil && msg.err == nil {
+			m.logSuccess("Squashed fixup into %s", msg.fixup.Hash)
+			m.switchAfterCommit(fmt.Sprintf("Fixed up %s", msg.fixup.Hash), msg.switchTo)
+			return nil
+		}
+		// Conflicts are resolved like any other rebase, then the switch is done by hand
+		m.handleOperationResult(OperationRebase, msg.err)
+		if m.operation == OperationRebase && msg.previousBranch != msg.branch {
+			m.logInfo("Checked out %s to resolve rebase conflicts (was on %s)", msg.branch, msg.previousBranch)
+		}
+		if tip, ok := m.gitService.GetRebaseBackup(msg.branch); ok {
+			m.logDebug("Pre-rebase tip of %s recorded as %s (%s: undo)", msg.branch, tip[:8], m.keys.UndoRebase.Help().Key)
+		}
+	}
+	return nil
+}
+
+// undoRebase restores a branch to the tip recorded before the tool last rebased it
+func (m *model) undoRebase(branch Branch) {
+	if m.worktreeBusy("undo a rebase") {
+		return
+	}
+	m.logInfo("Undoing rebase of %s", branch.Name)
+	if err := m.gitService.UndoRebase(branch.Name); err != nil {
+		m.logError("%v", err)
+		m.message = fmt.Sprintf("Error: %v", err)
+		return
+	}
+
+	m.logSuccess("Restored %s to its pre-rebase tip", branch.Name)
+	m.message = fmt.Sprintf("Undid rebase of %s", branch.Name)
+	m.refreshAfterOperation()
+}
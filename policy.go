@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CommitPolicy holds the rules messages written in the commit modal are checked against.
+// Every rule is off until configured.
+type CommitPolicy struct {
+	SubjectMinLength int               `json:"subject_min_length"` // 0 for no minimum
+	SubjectMaxLength int               `json:"subject_max_length"` // 0 for no maximum
+	Conventional     bool              `json:"conventional"`       // Subject must read "type(scope): summary"
+	Types            []string          `json:"types"`              // Allowed Conventional Commits types, empty for the usual ones
+	RequireScope     bool              `json:"require_scope"`      // Subject must name a (scope)
+	Scopes           []string          `json:"scopes"`             // Allowed scopes, empty for any
+	RequireTicket    bool              `json:"require_ticket"`     // Message must mention the ticket found in the branch name by ticket_pattern
+	NoTrailingPeriod bool              `json:"no_trailing_period"` // Subject must not end with a period
+	Trailers         []string          `json:"trailers"`           // Trailers the description must end with, e.g. "Signed-off-by"
+	Severity         map[string]string `json:"severity"`           // Rule name to "error" (blocks committing) or "warning"
+
+	ticketPattern *regexp.Regexp
+}
+
+// PolicySeverity decides whether a broken rule blocks committing
+type PolicySeverity int
+
+const (
+	SeverityWarning PolicySeverity = iota
+	SeverityError
+)
+
+// policyRules lists the rule names with their default severity
+var policyRules = map[string]PolicySeverity{
+	"subject-length":  SeverityWarning,
+	"conventional":    SeverityError,
+	"scope":           SeverityError,
+	"ticket":          SeverityError,
+	"trailing-period": SeverityWarning,
+	"trailers":        SeverityError,
+}
+
+// defaultCommitTypes are the Conventional Commits types allowed when none are configured
+var defaultCommitTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+var (
+	conventionalPattern = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?: (.*)$`)
+	trailerPattern      = regexp.MustCompile(`^([A-Za-z0-9-]+):\s*\S`)
+)
+
+// PolicyViolation is a rule the commit message breaks
+type PolicyViolation struct {
+	Rule     string
+	Severity PolicySeverity
+	Message  string
+}
+
+var (
+	policyErrorStyle = lipgloss.NewStyle()
+
+	policyWarningStyle = lipgloss.NewStyle()
+)
+
+// resolveCommitPolicy validates the configured commit policy and compiles its ticket pattern
+func resolveCommitPolicy(config Config) (*CommitPolicy, error) {
+	policy := config.CommitPolicy
+	pattern, err := regexp.Compile(config.TicketPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ticket_pattern: %v", err)
+	}
+	policy.ticketPattern = pattern
+
+	if policy.SubjectMinLength < 0 || policy.SubjectMaxLength < 0 {
+		return nil, fmt.Errorf("commit_policy subject lengths can't be negative")
+	}
+	if policy.SubjectMaxLength > 0 && policy.SubjectMinLength > policy.SubjectMaxLength {
+		return nil, fmt.Errorf("commit_policy subject_min_length is above subject_max_length")
+	}
+	if !policy.Conventional && (len(policy.Types) > 0 || len(policy.Scopes) > 0 || policy.RequireScope) {
+		return nil, fmt.Errorf("commit_policy types, scopes and require_scope need conventional to be enabled")
+	}
+	for rule, severity := range policy.Severity {
+		if _, ok := policyRules[rule]; !ok {
+			var names []string
+			for name := range policyRules {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return nil, fmt.Errorf("unknown commit_policy rule %q (expected one of %s)", rule, strings.Join(names, ", "))
+		}
+		if severity != "error" && severity != "warning" {
+			return nil, fmt.Errorf("commit_policy severity of %s must be error or warning, not %q", rule, severity)
+		}
+	}
+	return &policy, nil
+}
+
+// severity returns how serious breaking rule is
+func (p *CommitPolicy) severity(rule string) PolicySeverity {
+	switch p.Severity[rule] {
+	case "error":
+		return SeverityError
+	case "warning":
+		return SeverityWarning
+	}
+	return policyRules[rule]
+}
+
+// ticket returns the ticket key in a branch name, if any
+func (p *CommitPolicy) ticket(branch string) string {
+	if p == nil || p.ticketPattern == nil {
+		return ""
+	}
+	return p.ticketPattern.FindString(branch)
+}
+
+// Check returns the rules a commit message on branch breaks
+func (p *CommitPolicy) Check(subject, description, branch string) []PolicyViolation {
+	if p == nil {
+		return nil
+	}
+
+	var violations []PolicyViolation
+	add := func(rule, format string, args ...interface{}) {
+		violations = append(violations, PolicyViolation{
+			Rule:     rule,
+			Severity: p.severity(rule),
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	subject = strings.TrimSpace(subject)
+	length := len([]rune(subject))
+	if p.SubjectMaxLength > 0 && length > p.SubjectMaxLength {
+		add("subject-length", "Subject is %d characters, keep it to %d", length, p.SubjectMaxLength)
+	}
+	if p.SubjectMinLength > 0 && length < p.SubjectMinLength {
+		add("subject-length", "Subject is %d characters, write at least %d", length, p.SubjectMinLength)
+	}
+
+	if p.Conventional {
+		types := p.Types
+		if len(types) == 0 {
+			types = defaultCommitTypes
+		}
+		if match := conventionalPattern.FindStringSubmatch(subject); match == nil {
+			add("conventional", "Subject should read \"type(scope): summary\"")
+		} else {
+			commitType, scope := match[1], match[2]
+			if !slices.Contains(types, commitType) {
+				add("conventional", "Unknown type %q (expected %s)", commitType, strings.Join(types, ", "))
+			}
+			if strings.TrimSpace(match[4]) == "" {
+				add("conventional", "Summary is missing after \"%s:\"", commitType)
+			}
+			switch {
+			case scope == "" && p.RequireScope:
+				add("scope", "Add a scope: %s(scope): summary", commitType)
+			case scope != "" && len(p.Scopes) > 0 && !slices.Contains(p.Scopes, scope):
+				add("scope", "Unknown scope %q (expected %s)", scope, strings.Join(p.Scopes, ", "))
+			}
+		}
+	}
+
+	if p.RequireTicket {
+		if ticket := p.ticket(branch); ticket != "" {
+			message := strings.ToUpper(subject + "\n" + description)
+			if !strings.Contains(message, strings.ToUpper(ticket)) {
+				add("ticket", "Mention %s from the branch name", ticket)
+			}
+		}
+	}
+
+	if p.NoTrailingPeriod && strings.HasSuffix(subject, ".") {
+		add("trailing-period", "Subject ends with a period")
+	}
+
+	present := messageTrailers(description)
+	for _, trailer := range p.Trailers {
+		if !present[strings.ToLower(trailer)] {
+			add("trailers", "Add a %s: trailer at the end of the description", trailer)
+		}
+	}
+	return violations
+}
+
+// messageTrailers returns the lowercased keys of the "Key: value" lines in the last paragraph
+func messageTrailers(description string) map[string]bool {
+	paragraphs := strings.Split(strings.TrimSpace(description), "\n\n")
+	trailers := make(map[string]bool)
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		if match := trailerPattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			trailers[strings.ToLower(match[1])] = true
+		}
+	}
+	return trailers
+}
+
+// countViolations counts the violations that stop a commit and those that only warn
+func countViolations(violations []PolicyViolation) (errors, warnings int) {
+	for _, violation := range violations {
+		if violation.Severity == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+// renderViolations lists violations under the commit message fields
+func renderViolations(violations []PolicyViolation) string {
+	lines := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		if violation.Severity == SeverityError {
+			lines = append(lines, policyErrorStyle.Render("✗ "+violation.Message))
+		} else {
+			lines = append(lines, policyWarningStyle.Render("⚠ "+violation.Message))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
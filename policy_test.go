@@ -0,0 +1,88 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// violatedRules lists the rule of each violation, errors marked with a "!"
+func violatedRules(violations []PolicyViolation) []string {
+	rules := []string{}
+	for _, violation := range violations {
+		rule := violation.Rule
+		if violation.Severity == SeverityError {
+			rule += "!"
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func TestCommitPolicyCheck(t *testing.T) {
+	config := DefaultConfig
+	config.CommitPolicy = CommitPolicy{
+		SubjectMinLength: 10,
+		SubjectMaxLength: 30,
+		Conventional:     true,
+		Scopes:           []string{"ui", "git"},
+		RequireTicket:    true,
+		NoTrailingPeriod: true,
+		Trailers:         []string{"Signed-off-by"},
+		Severity:         map[string]string{"ticket": "warning"},
+	}
+	policy, err := resolveCommitPolicy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		subject, description, branch string
+		want                         []string
+	}{
+		{"fix(ui): Keep the cursor", "Signed-off-by: A <a@b.c>", "main", []string{}},
+		{"fix: short", "Signed-off-by: A <a@b.c>", "main", []string{}},
+		{"fix:", "Signed-off-by: A <a@b.c>", "main", []string{"subject-length", "conventional!"}},
+		{"Keep the cursor in place", "Signed-off-by: A <a@b.c>", "main", []string{"conventional!"}},
+		{"wip(ui): Keep the cursor", "Signed-off-by: A <a@b.c>", "main", []string{"conventional!"}},
+		{"fix(db): Keep the cursor.", "Signed-off-by: A <a@b.c>", "main", []string{"scope!", "trailing-period"}},
+		{"fix(ui): Keep the cursor in view while scrolling", "", "main", []string{"subject-length", "trailers!"}},
+		{"fix(ui): Keep the cursor", "Signed-off-by: A <a@b.c>", "feature/ABC-12-cursor", []string{"ticket"}},
+		{"fix(ui): Keep the cursor", "For abc-12\n\nSigned-off-by: A <a@b.c>", "feature/ABC-12-cursor", []string{}},
+		{"fix(ui): Keep the cursor", "Signed-off-by: A <a@b.c>\n\nMore text", "main", []string{"trailers!"}},
+	}
+	for _, test := range tests {
+		got := violatedRules(policy.Check(test.subject, test.description, test.branch))
+		if !slices.Equal(got, test.want) {
+			t.Errorf("Check(%q, %q, %q) = %v, want %v", test.subject, test.description, test.branch, got, test.want)
+		}
+	}
+}
+
+func TestCommitPolicyOffByDefault(t *testing.T) {
+	policy, err := resolveCommitPolicy(DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := policy.Check("wip.", "", "ABC-1"); len(got) != 0 {
+		t.Errorf("default policy found %v", violatedRules(got))
+	}
+	if got := (*CommitPolicy)(nil).Check("wip", "", "main"); got != nil {
+		t.Errorf("nil policy found %v", got)
+	}
+}
+
+func TestResolveCommitPolicyErrors(t *testing.T) {
+	for name, policy := range map[string]CommitPolicy{
+		"negative length":      {SubjectMinLength: -1},
+		"min above max":        {SubjectMinLength: 50, SubjectMaxLength: 10},
+		"scopes without types": {Scopes: []string{"ui"}},
+		"unknown rule":         {Severity: map[string]string{"spelling": "error"}},
+		"unknown severity":     {Severity: map[string]string{"ticket": "fatal"}},
+	} {
+		config := DefaultConfig
+		config.CommitPolicy = policy
+		if _, err := resolveCommitPolicy(config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
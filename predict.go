@@ -0,0 +1,309 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SwitchPrediction is what switching to, or merging, a branch is expected to run into.
+// It is worked out with git merge-tree so neither the worktree nor the index is touched.
+type SwitchPrediction struct {
+	CarryConflicts   []string // Files where local changes would conflict with the target
+	CurrentConflicts []string // Files that conflict when merging the target into the current branch
+	Base             string   // Base branch of the target, if one was found
+	BaseConflicts    []string // Files that conflict when merging the target into its base
+	Problem          string   // Why conflicts couldn't be predicted, empty when they could
+}
+
+// unknownPrediction stands in for a prediction that couldn't be made
+func unknownPrediction(err error) *SwitchPrediction {
+	return &SwitchPrediction{Problem: err.Error()}
+}
+
+// HasConflicts reports whether any prediction found a conflict
+func (p *SwitchPrediction) HasConflicts() bool {
+	return p != nil && (len(p.CarryConflicts) > 0 || len(p.CurrentConflicts) > 0 || len(p.BaseConflicts) > 0)
+}
+
+// Badge returns a short marker for the branch table
+func (p *SwitchPrediction) Badge() string {
+	switch {
+	case p == nil:
+		return ""
+	case len(p.CarryConflicts) > 0:
+		return "✗ "
+	case len(p.CurrentConflicts) > 0 || len(p.BaseConflicts) > 0:
+		return "⚠ "
+	case p.Problem != "":
+		return "? "
+	default:
+		return ""
+	}
+}
+
+// Warnings describes each predicted conflict in a sentence
+func (p *SwitchPrediction) Warnings(targetBranch string) []string {
+	if p == nil {
+		return nil
+	}
+
+	var warnings []string
+	if len(p.CarryConflicts) > 0 {
+		warnings = append(warnings, fmt.Sprintf("Your local changes conflict with '%s' in: %s",
+			targetBranch, strings.Join(p.CarryConflicts, ", ")))
+	}
+	if len(p.CurrentConflicts) > 0 {
+		warnings = append(warnings, fmt.Sprintf("Merging '%s' into the current branch would conflict in: %s",
+			targetBranch, strings.Join(p.CurrentConflicts, ", ")))
+	}
+	if len(p.BaseConflicts) > 0 {
+		warnings = append(warnings, fmt.Sprintf("Merging '%s' into %s would conflict in: %s",
+			targetBranch, p.Base, strings.Join(p.BaseConflicts, ", ")))
+	}
+	if p.Problem != "" {
+		warnings = append(warnings, fmt.Sprintf("Can't predict conflicts with '%s': %s", targetBranch, p.Problem))
+	}
+	return warnings
+}
+
+var (
+	predictionWarningStyle = lipgloss.NewStyle().
+		Bold(true)
+)
+
+// worktreeSnapshot records the tracked local changes as a commit on top of HEAD without
+// touching the worktree, index or stash list. It returns "" when there are no changes.
+func (g *GitService) worktreeSnapshot() (string, error) {
+	stashCmd := gitCommand("stash", "create")
+	output, err := stashCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot local changes: %v", err)
+	}
+
+	stash := strings.TrimSpace(string(output))
+	if stash == "" {
+		return "", nil
+	}
+
+	// Flatten into a single-parent commit so HEAD is the only merge base
+	return g.commitTree(stash+"^{tree}", "HEAD")
+}
+
+// commitTree creates a dangling commit with the given tree and parent
+func (g *GitService) commitTree(tree, parent string) (string, error) {
+	cmd := gitCommand("commit-tree", tree, "-p", parent, "-m", "recent-branches: switch prediction")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to create prediction commit: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// mergeTreeConflicts returns the files that would conflict if theirs were merged into ours
+func (g *GitService) mergeTreeConflicts(ours, theirs string) ([]string, error) {
+	cmd := gitCommand("merge-tree", "--write-tree", "--name-only", "--no-messages", ours, theirs)
+	output, err := cmd.Output()
+	if err == nil {
+		return nil, nil
+	}
+
+	// Exit status 1 means the merge has conflicts; the first line is the resulting tree
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		var files []string
+		for _, line := range lines[1:] {
+			if line = strings.TrimSpace(line); line != "" {
+				files = append(files, line)
+			}
+		}
+		return files, nil
+	}
+
+	return nil, fmt.Errorf("failed to predict merge of %s into %s: %v", theirs, ours, err)
+}
+
+// predictSwitch works out the prediction for one branch given a snapshot of local changes.
+// It fails rather than report no conflicts when git can't check, e.g. before 2.38.
+func (g *GitService) predictSwitch(branchName, currentBranch, snapshot string) (*SwitchPrediction, error) {
+	target := gitRefForBranch(branchName)
+	prediction := &SwitchPrediction{}
+
+	// Carrying local changes over is a three-way merge of HEAD, the target and the worktree.
+	// Re-parenting the target's tree onto HEAD makes HEAD the merge base, as checkout -m does.
+	if snapshot != "" {
+		retargeted, err := g.commitTree(target+"^{tree}", "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		if prediction.CarryConflicts, err = g.mergeTreeConflicts(retargeted, snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	if currentBranch != "" && currentBranch != "HEAD" {
+		var err error
+		if prediction.CurrentConflicts, err = g.mergeTreeConflicts(currentBranch, target); err != nil {
+			return nil, err
+		}
+	}
+
+	if base, _, err := g.findBaseBranch(target); err == nil && base != "root commit" && base != target {
+		prediction.Base = base
+		if base != currentBranch {
+			if prediction.BaseConflicts, err = g.mergeTreeConflicts(base, target); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return prediction, nil
+}
+
+// PredictSwitch predicts the conflicts switching to a single branch would run into
+func (g *GitService) PredictSwitch(branchName string) (*SwitchPrediction, error) {
+	snapshot, err := g.worktreeSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	currentBranch, _ := g.GetCurrentBranch()
+	return g.predictSwitch(branchName, currentBranch, snapshot)
+}
+
+// Most predictions kept before the cache starts over
+const maxCachedPredictions = 500
+
+// predictionCache keeps predictions by the repository state and branch they were made for,
+// so moving back and forth over the table doesn't repeat the merges
+type predictionCache struct {
+	mu      sync.Mutex
+	entries map[string]*SwitchPrediction
+}
+
+func newPredictionCache() *predictionCache {
+	return &predictionCache{entries: make(map[string]*SwitchPrediction)}
+}
+
+// predictionState identifies everything a prediction depends on: the branch tips, HEAD and
+// the local changes. It only reads, unlike making a prediction, which writes objects.
+func (g *GitService) predictionState() (string, error) {
+	refs, err := gitCommand("for-each-ref", "--format=%(objectname) %(refname)", "refs/heads", "refs/remotes").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list branch tips: %v", err)
+	}
+	head, err := gitCommand("rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %v", err)
+	}
+	changes, err := gitCommand("diff", "HEAD", "--binary").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff local changes: %v", err)
+	}
+
+	hash := sha256.New()
+	for _, part := range [][]byte{refs, head, changes} {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// PredictSwitchCached returns the prediction for a branch, making it only if the repository
+// changed since it was last made. It also returns the state the prediction holds for.
+func (g *GitService) PredictSwitchCached(cache *predictionCache, branchName string) (string, *SwitchPrediction) {
+	state, err := g.predictionState()
+	if err != nil {
+		return "", unknownPrediction(err)
+	}
+
+	key := state + " " + branchName
+	cache.mu.Lock()
+	prediction, ok := cache.entries[key]
+	cache.mu.Unlock()
+	if ok {
+		return state, prediction
+	}
+
+	prediction, err = g.PredictSwitch(branchName)
+	if err != nil {
+		prediction = unknownPrediction(err)
+	}
+
+	cache.mu.Lock()
+	if len(cache.entries) >= maxCachedPredictions {
+		cache.entries = make(map[string]*SwitchPrediction)
+	}
+	cache.entries[key] = prediction
+	cache.mu.Unlock()
+	return state, prediction
+}
+
+// predictionMsg carries the prediction made in the background for a branch
+type predictionMsg struct {
+	branch     string
+	state      string // Repository state the prediction holds for
+	prediction *SwitchPrediction
+}
+
+// predictSelected predicts conflicts for the selected branch in the background, once per
+// selection and refresh. Only the selected row is predicted, as each takes several merges.
+func (m *model) predictSelected() tea.Cmd {
+	branch, ok := m.selectedBranch()
+	if !ok || branch.Name == m.predictionChecked {
+		return nil
+	}
+	m.predictionChecked = branch.Name
+	if currentBranch, err := m.gitService.GetCurrentBranch(); err == nil && gitRefForBranch(branch.Name) == currentBranch {
+		return nil
+	}
+
+	gitService, cache := m.gitService, m.predictionCache
+	return func() tea.Msg {
+		state, prediction := gitService.PredictSwitchCached(cache, branch.Name)
+		return predictionMsg{branch: branch.Name, state: state, prediction: prediction}
+	}
+}
+
+// predictCommitModal predicts conflicts in the background for a commit modal that was opened
+// before its target had a prediction
+func (m *model) predictCommitModal() tea.Cmd {
+	if !m.commitModal.NeedsPrediction() {
+		return nil
+	}
+	m.commitModal.predicting = true
+
+	gitService, cache, target := m.gitService, m.predictionCache, m.commitModal.targetBranch
+	return func() tea.Msg {
+		state, prediction := gitService.PredictSwitchCached(cache, target)
+		return predictionMsg{branch: target, state: state, prediction: prediction}
+	}
+}
+
+// handlePredictionMsg shows a prediction on its row. Predictions made for an older state of
+// the repository are dropped.
+func (m *model) handlePredictionMsg(msg predictionMsg) {
+	if msg.state != m.predictionState {
+		m.predictionState = msg.state
+		m.predictions = make(map[string]*SwitchPrediction)
+	}
+	m.predictions[msg.branch] = msg.prediction
+	if msg.prediction.Problem != "" {
+		m.logDebug("Can't predict conflicts for %s: %s", msg.branch, msg.prediction.Problem)
+	}
+	m.commitModal.SetPrediction(msg.branch, msg.prediction)
+	m.applyPredictions()
+	m.tableManager.RefreshRows(m.branches)
+}
+
+// applyPredictions puts the predictions known for the current state on the branches
+func (m *model) applyPredictions() {
+	for i, branch := range m.branches {
+		m.branches[i].Prediction = m.predictions[branch.Name]
+	}
+}
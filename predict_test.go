@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// conflictingBranches sets up a feature branch and main that both change README
+func conflictingBranches(t *testing.T) {
+	t.Helper()
+	gitRun(t, "checkout", "-q", "-b", "feature")
+	commitFile(t, "README", "feature\n", "Feature change")
+	gitRun(t, "checkout", "-q", "main")
+	commitFile(t, "README", "main\n", "Main change")
+}
+
+func TestPredictSwitchFindsConflicts(t *testing.T) {
+	newTestRepo(t)
+	conflictingBranches(t)
+	if err := os.WriteFile("README", []byte("local\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	prediction, err := NewGitService().PredictSwitch("feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prediction.CarryConflicts) != 1 || len(prediction.CurrentConflicts) != 1 {
+		t.Errorf("got %+v, want README to conflict with local changes and main", prediction)
+	}
+	if prediction.Badge() != "✗ " {
+		t.Errorf("Badge() = %q, want ✗", prediction.Badge())
+	}
+}
+
+func TestPredictSwitchFailsWithoutCommitter(t *testing.T) {
+	newTestRepo(t)
+	conflictingBranches(t)
+	if err := os.WriteFile("README", []byte("local\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// commit-tree can't snapshot the local changes without a committer
+	t.Setenv("GIT_COMMITTER_NAME", "")
+
+	if _, err := NewGitService().PredictSwitch("feature"); err == nil {
+		t.Fatal("expected an error instead of a prediction without conflicts")
+	}
+
+	_, prediction := NewGitService().PredictSwitchCached(newPredictionCache(), "feature")
+	if prediction.Badge() != "? " {
+		t.Errorf("Badge() = %q, want ? for an unknown prediction", prediction.Badge())
+	}
+	warnings := prediction.Warnings("feature")
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "Can't predict conflicts with 'feature'") {
+		t.Errorf("Warnings() = %q", warnings)
+	}
+}
+
+func TestPredictSwitchCachedReusesPredictions(t *testing.T) {
+	newTestRepo(t)
+	conflictingBranches(t)
+	git := NewGitService()
+	cache := newPredictionCache()
+
+	state, first := git.PredictSwitchCached(cache, "feature")
+	again, second := git.PredictSwitchCached(cache, "feature")
+	if state != again || first != second {
+		t.Error("expected the cached prediction for an unchanged repository")
+	}
+
+	// Local changes make it a different state
+	if err := os.WriteFile("README", []byte("local\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	changed, third := git.PredictSwitchCached(cache, "feature")
+	if changed == state || third == first {
+		t.Error("expected a new prediction after local changes")
+	}
+	if len(third.CarryConflicts) != 1 {
+		t.Errorf("got %+v, want README to conflict with local changes", third)
+	}
+}
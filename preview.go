@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PreviewMode selects what the preview pane below the table shows
+type PreviewMode int
+
+const (
+	PreviewCommits PreviewMode = iota // Recent commits on the branch
+	PreviewDiff                       // Diff of the branch against its merge base
+)
+
+var (
+	// Diff preview styles
+	diffContainerStyle = lipgloss.NewStyle().
+				BorderStyle(lipgloss.NormalBorder()).
+				Padding(0, 1)
+
+	diffFocusedStyle = lipgloss.NewStyle().
+				BorderStyle(lipgloss.NormalBorder()).
+				Padding(0, 1)
+
+	diffAddStyle = lipgloss.NewStyle()
+
+	diffDelStyle = lipgloss.NewStyle()
+
+	diffHunkStyle = lipgloss.NewStyle()
+
+	diffMetaStyle = lipgloss.NewStyle().
+			Bold(true)
+)
+
+// DiffViewer shows a scrollable diffstat and unified diff for a branch
+type DiffViewer struct {
+	viewport viewport.Model
+	diff     *BranchDiff
+	focused  bool
+}
+
+func NewDiffViewer() *DiffViewer {
+	return &DiffViewer{
+		viewport: viewport.New(120, 12),
+	}
+}
+
+// SetDiff replaces the displayed diff and scrolls back to the top
+func (dv *DiffViewer) SetDiff(diff *BranchDiff) {
+	dv.diff = diff
+	if diff == nil {
+		dv.viewport.SetContent("")
+		return
+	}
+
+	var lines []string
+	if diff.Stat == "" {
+		lines = append(lines, "No changes against "+diff.Base)
+	} else {
+		lines = append(lines, diff.Stat, "")
+		lines = append(lines, colorizeDiff(diff.Patch)...)
+	}
+
+	dv.viewport.SetContent(strings.Join(lines, "\n"))
+	dv.viewport.GotoTop()
+}
+
+// SetSize resizes the diff viewport
+func (dv *DiffViewer) SetSize(width, height int) {
+	if width > 0 {
+		dv.viewport.Width = width
+	}
+	dv.viewport.Height = height
+}
+
+func (dv *DiffViewer) ToggleFocus() {
+	dv.focused = !dv.focused
+}
+
+func (dv *DiffViewer) ScrollUp() {
+	dv.viewport.ScrollUp(1)
+}
+
+func (dv *DiffViewer) ScrollDown() {
+	dv.viewport.ScrollDown(1)
+}
+
+func (dv *DiffViewer) PageUp() {
+	dv.viewport.PageUp()
+}
+
+func (dv *DiffViewer) PageDown() {
+	dv.viewport.PageDown()
+}
+
+func (dv *DiffViewer) View() string {
+	style := diffContainerStyle
+	if dv.focused {
+		style = diffFocusedStyle
+	}
+
+	if dv.diff == nil {
+		return style.Render("No diff loaded")
+	}
+
+	title := fmt.Sprintf("Diff vs %s (merge base %s):", dv.diff.Base, truncateString(dv.diff.MergeBase, 8))
+	scroll := timestampStyle.Render(fmt.Sprintf(" %3.0f%%", dv.viewport.ScrollPercent()*100))
+
+	return style.Render(lipgloss.JoinVertical(
+		lipgloss.Left,
+		commitTitleStyle.Render(title)+scroll,
+		dv.viewport.View(),
+	))
+}
+
+// colorizeDiff applies add/delete/hunk colors to unified diff lines
+func colorizeDiff(patch string) []string {
+	var lines []string
+	for _, line := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"),
+			strings.HasPrefix(line, "diff --git"), strings.HasPrefix(line, "index "):
+			lines = append(lines, diffMetaStyle.Render(line))
+		case strings.HasPrefix(line, "@@"):
+			lines = append(lines, diffHunkStyle.Render(line))
+		case strings.HasPrefix(line, "+"):
+			lines = append(lines, diffAddStyle.Render(line))
+		case strings.HasPrefix(line, "-"):
+			lines = append(lines, diffDelStyle.Render(line))
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// RemoteOperation identifies a command that talks to a remote
+type RemoteOperation int
+
+const (
+	RemoteFetch RemoteOperation = iota
+	RemotePull
+	RemotePullRebase
+	RemotePush
+)
+
+func (op RemoteOperation) String() string {
+	switch op {
+	case RemoteFetch:
+		return "fetch"
+	case RemotePull:
+		return "pull"
+	case RemotePullRebase:
+		return "pull --rebase"
+	case RemotePush:
+		return "push"
+	default:
+		return "unknown"
+	}
+}
+
+// remoteProgressMsg carries one line of git's progress output
+type remoteProgressMsg struct {
+	line string
+}
+
+// remoteDoneMsg is sent once a remote operation has finished
+type remoteDoneMsg struct {
+	op     RemoteOperation
+	branch string
+	err    error
+}
+
+// remoteName is the remote used for pushing and pulling
+const remoteName = "origin"
+
+// runWithProgress runs git, passing each progress line to progress as it arrives
+func (g *GitService) runWithProgress(progress func(string), args ...string) error {
+	return g.streamCommand(gitCommand(args...), progress)
+}
+
+// streamCommand is runWithProgress for a git command that needs more setup, such as its own environment
+func (g *GitService) streamCommand(cmd *tracedCmd, progress func(string)) error {
+	args := cmd.Args[1:]
+	// Never block the TUI on a credential prompt
+	cmd.Env = append(cmd.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	// Name the subcommand in errors, not options like -C <dir> before it
+	name := args[0]
+	for i := 0; i+2 < len(args) && (args[i] == "-C" || args[i] == "-c"); i += 2 {
+		name = args[i+2]
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start git %s: %v", name, err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		writer.Close()
+		done <- err
+	}()
+
+	// Keep the last few lines to explain failures
+	var tail []string
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(scanProgressLines)
+	for scanner.Scan() {
+		// Drop the terminal codes git uses to clear progress lines
+		line := strings.TrimSpace(ansi.Strip(scanner.Text()))
+		if line == "" {
+			continue
+		}
+		progress(line)
+		tail = append(tail, line)
+		if len(tail) > 5 {
+			tail = tail[1:]
+		}
+	}
+
+	if err := <-done; err != nil {
+		return fmt.Errorf("git %s failed: %v\nOutput: %s", name, err, strings.Join(tail, "\n"))
+	}
+	return nil
+}
+
+// scanProgressLines splits on both \n and the \r git uses to redraw progress in place
+func scanProgressLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// hasUpstream reports whether a local branch tracks a remote branch
+func (g *GitService) hasUpstream(branchName string) bool {
+	cmd := gitCommand("rev-parse", "--abbrev-ref", "--verify", "-q", branchName+"@{upstream}")
+	return cmd.Run() == nil
+}
+
+// upstreamOf returns the remote and remote ref a local branch tracks
+func (g *GitService) upstreamOf(branchName string) (string, string, error) {
+	cmd := gitCommand("for-each-ref", "--format=%(upstream:remotename) %(upstream:remoteref)", "refs/heads/"+branchName)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the upstream of %s: %v", branchName, err)
+	}
+	remote, ref, ok := strings.Cut(strings.TrimSpace(string(output)), " ")
+	if !ok || remote == "" || ref == "" {
+		return "", "", fmt.Errorf("branch %s has no upstream to pull from", branchName)
+	}
+	return remote, ref, nil
+}
+
+// FetchRemote fetches all remotes, optionally pruning deleted remote branches
+func (g *GitService) FetchRemote(prune bool, progress func(string)) error {
+	args := []string{"fetch", "--all", "--progress"}
+	if prune {
+		args = append(args, "--prune")
+	}
+	return g.runWithProgress(progress, args...)
+}
+
+// PushBranch pushes a local branch, setting up tracking if it has no upstream yet
+func (g *GitService) PushBranch(branchName string, progress func(string)) error {
+	args := []string{"push", "--progress"}
+	if !g.hasUpstream(branchName) {
+		progress(fmt.Sprintf("No upstream for %s, setting it to %s/%s", branchName, remoteName, branchName))
+		args = append(args, "--set-upstream")
+	}
+	args = append(args, remoteName, branchName)
+	return g.runWithProgress(progress, args...)
+}
+
+// PullBranch updates a local branch from its upstream. The current branch is pulled
+// fast-forward only or with rebase; other branches can only be fast-forwarded.
+func (g *GitService) PullBranch(branchName string, rebase bool, progress func(string)) error {
+	if !g.hasUpstream(branchName) {
+		return fmt.Errorf("branch %s has no upstream to pull from", branchName)
+	}
+
+	currentBranch, _ := g.GetCurrentBranch()
+	if currentBranch == branchName {
+		mode := "--ff-only"
+		if rebase {
+			mode = "--rebase"
+		}
+		return g.runWithProgress(progress, "pull", "--progress", mode)
+	}
+
+	if rebase {
+		return fmt.Errorf("pull --rebase needs %s to be checked out", branchName)
+	}
+
+	remote, ref, err := g.upstreamOf(branchName)
+	if err != nil {
+		return err
+	}
+
+	// Fetching into the local ref only succeeds as a fast-forward
+	return g.runWithProgress(progress, "fetch", "--progress", remote, ref+":refs/heads/"+branchName)
+}
+
+// waitForEvent delivers the next progress or completion message of a background operation
+func waitForEvent(events <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-events
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// startRemoteOperation runs a remote operation in the background, streaming its output into the logs
+func (m *model) startRemoteOperation(op RemoteOperation) tea.Cmd {
+	if m.remoteEvents != nil {
+		m.message = "Another remote operation is still running"
+		return nil
+	}
+	// The background fetch updates the same refs and FETCH_HEAD
+	if m.backgroundFetching {
+		m.message = "A background fetch is running, try again in a moment"
+		return nil
+	}
+	// Pulling the checked out branch updates the worktree
+	if (op == RemotePull || op == RemotePullRebase) && m.worktreeBusy(op.String()) {
+		return nil
+	}
+
+	var branchName string
+	if op != RemoteFetch {
+		branch, ok := m.selectedBranch()
+		if !ok {
+			return nil
+		}
+		if branch.IsRemote {
+			m.logError("Cannot %s remote branch %s, check it out first", op, branch.Name)
+			m.message = fmt.Sprintf("Select a local branch to %s", op)
+			return nil
+		}
+		branchName = branch.Name
+	}
+
+	if branchName != "" {
+		m.logInfo("Starting %s of %s", op, branchName)
+		m.message = fmt.Sprintf("Running %s of %s...", op, branchName)
+	} else {
+		m.logInfo("Starting %s", op)
+		m.message = fmt.Sprintf("Running %s...", op)
+	}
+
+	events := make(chan tea.Msg, 64)
+	m.remoteEvents = events
+	gitService := m.gitService
+
+	go func() {
+		defer close(events)
+		progress := func(line string) {
+			events <- remoteProgressMsg{line: line}
+		}
+
+		var err error
+		switch op {
+		case RemoteFetch:
+			err = gitService.FetchRemote(true, progress)
+		case RemotePull:
+			err = gitService.PullBranch(branchName, false, progress)
+		case RemotePullRebase:
+			err = gitService.PullBranch(branchName, true, progress)
+		case RemotePush:
+			err = gitService.PushBranch(branchName, progress)
+		}
+		events <- remoteDoneMsg{op: op, branch: branchName, err: err}
+	}()
+
+	return waitForEvent(events)
+}
+
+// handleRemoteMsg logs streamed progress and reports the outcome of a remote operation
+func (m *model) handleRemoteMsg(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case remoteProgressMsg:
+		m.logProgress(msg.line)
+		return waitForEvent(m.remoteEvents)
+
+	case remoteDoneMsg:
+		m.remoteEvents = nil
+
+		target := msg.op.String()
+		if msg.branch != "" {
+			target = fmt.Sprintf("%s of %s", msg.op, msg.branch)
+		}
+
+		if msg.err != nil {
+			m.logError("%s failed: %v", target, msg.err)
+			m.message = fmt.Sprintf("Error: %s failed", target)
+			// A pull --rebase can stop on conflicts
+			m.refreshOperationState()
+			return nil
+		}
+
+		m.logSuccess("%s completed", target)
+		m.message = fmt.Sprintf("%s completed", target)
+		m.refreshAfterOperation()
+	}
+	return nil
+}
+
+// RemoteUpdate describes a remote branch that moved during a background fetch
+type RemoteUpdate struct {
+	Ref        string // Short remote ref, e.g. origin/feature
+	OldHash    string // Empty for branches that are new on the remote
+	NewHash    string
+	NewCommits int
+	Authors    []string
+}
+
+// backgroundFetchTickMsg triggers the next background fetch
+type backgroundFetchTickMsg struct{}
+
+// backgroundFetchMsg carries the remote branches that moved during a background fetch
+type backgroundFetchMsg struct {
+	updates []RemoteUpdate
+	err     error
+}
+
+// snapshotRemoteRefs maps every remote-tracking ref to the commit it points at
+func (g *GitService) snapshotRemoteRefs() (map[string]string, error) {
+	cmd := gitCommand("for-each-ref", "--format=%(refname:short)|%(objectname)", "refs/remotes/")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote refs: %v", err)
+	}
+
+	refs := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "|", 2)
+		if len(parts) == 2 {
+			refs[parts[0]] = parts[1]
+		}
+	}
+	return refs, nil
+}
+
+// BackgroundFetch fetches quietly and reports which remote branches others moved and who they
+// were. Commits by the current user, such as their own pushes, are left out.
+func (g *GitService) BackgroundFetch() ([]RemoteUpdate, error) {
+	currentUser, _ := g.GetCurrentUser()
+
+	before, err := g.snapshotRemoteRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.FetchRemote(true, func(string) {}); err != nil {
+		return nil, err
+	}
+
+	after, err := g.snapshotRemoteRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []RemoteUpdate
+	for ref, newHash := range after {
+		oldHash := before[ref]
+		if oldHash == newHash || strings.HasSuffix(ref, "/HEAD") {
+			continue
+		}
+
+		// New branches only get their tip attributed, moved ones everything since the old tip
+		revRange := []string{"-1", newHash}
+		if oldHash != "" {
+			revRange = []string{oldHash + ".." + newHash}
+		}
+		args := append([]string{"log", "--format=%an|%ae"}, revRange...)
+		output, _ := gitCommand(args...).Output()
+
+		update := RemoteUpdate{Ref: ref, OldHash: oldHash, NewHash: newHash}
+		seen := make(map[string]bool)
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			author, email, _ := strings.Cut(line, "|")
+			if author == "" || (currentUser != "" && (email == currentUser || author == currentUser)) {
+				continue
+			}
+			update.NewCommits++
+			if !seen[author] {
+				seen[author] = true
+				update.Authors = append(update.Authors, author)
+			}
+		}
+		if update.NewCommits == 0 {
+			continue
+		}
+		updates = append(updates, update)
+	}
+
+	return updates, nil
+}
+
+// scheduleBackgroundFetch waits for the fetch interval before triggering the next fetch
+func scheduleBackgroundFetch(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return backgroundFetchTickMsg{}
+	})
+}
+
+// runBackgroundFetch fetches off the UI goroutine so input is never blocked
+func runBackgroundFetch(gitService *GitService) tea.Cmd {
+	return func() tea.Msg {
+		updates, err := gitService.BackgroundFetch()
+		return backgroundFetchMsg{updates: updates, err: err}
+	}
+}
+
+// handleBackgroundFetchMsg starts due fetches, records moved branches and schedules the next run
+func (m *model) handleBackgroundFetchMsg(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case backgroundFetchTickMsg:
+		// Leave the remote alone while the user is pushing, pulling or fetching
+		if m.remoteEvents != nil || m.backgroundFetching {
+			return scheduleBackgroundFetch(m.fetchInterval)
+		}
+		m.backgroundFetching = true
+		m.logDebug("Starting background fetch")
+		return runBackgroundFetch(m.gitService)
+
+	case backgroundFetchMsg:
+		m.backgroundFetching = false
+		next := scheduleBackgroundFetch(m.fetchInterval)
+
+		if msg.err != nil {
+			m.logError("Background fetch failed: %v", msg.err)
+			return next
+		}
+
+		if len(msg.updates) == 0 {
+			m.logDebug("Background fetch: no remote branches moved")
+			return next
+		}
+
+		for _, update := range msg.updates {
+			m.remoteUpdates[update.Ref] = update
+			if update.OldHash == "" {
+				m.logInfo("New remote branch %s pushed by %s", update.Ref, strings.Join(update.Authors, ", "))
+			} else {
+				m.logInfo("%s updated by %s (%d new commits)", update.Ref, strings.Join(update.Authors, ", "), update.NewCommits)
+			}
+		}
+		m.message = fmt.Sprintf("%d remote branches updated by others", len(msg.updates))
+
+		// Update the rows in place so the cursor and preview picks survive
+		selected, _ := m.selectedBranch()
+		if err := m.loadBranches(); err != nil {
+			m.logError("Failed to refresh branches: %v", err)
+			return next
+		}
+		m.tableManager.RefreshRows(m.branches)
+		if branch, ok := m.selectedBranch(); !ok || branch.Name != selected.Name {
+			m.loadCommitsForSelectedBranch()
+		}
+		return next
+	}
+	return nil
+}
+
+// applyRemoteUpdates marks branches whose remote counterpart moved in a background fetch
+func (m *model) applyRemoteUpdates() {
+	for i, branch := range m.branches {
+		name := strings.TrimSuffix(branch.Name, " (remote)")
+		if update, ok := m.remoteUpdates[remoteName+"/"+name]; ok {
+			m.branches[i].UpdatedBy = update.Authors
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBackgroundFetchSkipsOwnCommits(t *testing.T) {
+	dir := newTestRepo(t)
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	gitRun(t, "init", "-q", "--bare", remote)
+	gitRun(t, "remote", "add", "origin", remote)
+	gitRun(t, "push", "-q", "-u", "origin", "main")
+
+	// Push from another clone: one branch by the same user, one commit by someone else
+	other := filepath.Join(t.TempDir(), "other")
+	gitRun(t, "clone", "-q", remote, other)
+	t.Chdir(other)
+	gitRun(t, "config", "user.name", "Tester")
+	gitRun(t, "config", "user.email", "tester@example.com")
+	gitRun(t, "checkout", "-q", "-b", "mine")
+	commitFile(t, "mine", "mine\n", "My change")
+	gitRun(t, "push", "-q", "origin", "mine")
+	gitRun(t, "checkout", "-q", "main")
+	gitRun(t, "-c", "user.name=Other", "-c", "user.email=other@example.com", "commit", "-q", "--allow-empty", "-m", "Their change")
+	gitRun(t, "push", "-q", "origin", "main")
+
+	t.Chdir(dir)
+	updates, err := NewGitService().BackgroundFetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 1 {
+		t.Fatalf("got %d updates, want only origin/main: %+v", len(updates), updates)
+	}
+	update := updates[0]
+	if update.Ref != "origin/main" || update.NewCommits != 1 || !slices.Equal(update.Authors, []string{"Other"}) {
+		t.Errorf("got %+v, want origin/main with 1 commit by Other", update)
+	}
+}
+
+func TestPullBranchFetchesConfiguredUpstream(t *testing.T) {
+	dir := newTestRepo(t)
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	gitRun(t, "init", "-q", "--bare", remote)
+	gitRun(t, "remote", "add", "upstream", remote)
+	gitRun(t, "push", "-q", "upstream", "main:trunk")
+	gitRun(t, "fetch", "-q", "upstream")
+	gitRun(t, "branch", "--track", "local", "upstream/trunk")
+
+	// Move trunk on from another clone
+	other := filepath.Join(t.TempDir(), "other")
+	gitRun(t, "clone", "-q", "--branch", "trunk", remote, other)
+	t.Chdir(other)
+	gitRun(t, "config", "user.name", "Tester")
+	gitRun(t, "config", "user.email", "tester@example.com")
+	commitFile(t, "trunk", "trunk\n", "Trunk change")
+	gitRun(t, "push", "-q", "origin", "trunk")
+	want := gitRun(t, "rev-parse", "HEAD")
+
+	t.Chdir(dir)
+	if err := NewGitService().PullBranch("local", false, func(string) {}); err != nil {
+		t.Fatalf("PullBranch: %v", err)
+	}
+	if got := gitRun(t, "rev-parse", "local"); got != want {
+		t.Errorf("local is at %s, want %s", got, want)
+	}
+}
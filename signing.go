@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SigningStatus is how git is set up to sign commits
+type SigningStatus struct {
+	Enabled   bool   // commit.gpgsign is set
+	Format    string // gpg.format: openpgp, ssh or x509
+	Key       string // user.signingkey, empty to let the signing program choose
+	Available bool   // The key was found and can sign
+	Problem   string // Why the key can't be used
+}
+
+var (
+	signatureGoodStyle = lipgloss.NewStyle()
+
+	signatureBadStyle = lipgloss.NewStyle()
+
+	signatureUnverifiedStyle = lipgloss.NewStyle()
+
+	signatureNoneStyle = lipgloss.NewStyle()
+)
+
+// gitConfigValue returns a git config value, or "" if it isn't set
+func gitConfigValue(args ...string) string {
+	output, err := gitCommand(append([]string{"config"}, args...)...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// GetSigningStatus reads the signing config and checks that its key can be found
+func (g *GitService) GetSigningStatus() SigningStatus {
+	status := SigningStatus{
+		Enabled: gitConfigValue("--type=bool", "commit.gpgsign") == "true",
+		Format:  gitConfigValue("gpg.format"),
+		Key:     gitConfigValue("user.signingkey"),
+	}
+	if status.Format == "" {
+		status.Format = "openpgp"
+	}
+
+	var err error
+	switch status.Format {
+	case "ssh":
+		err = checkSSHSigningKey(status.Key)
+	case "x509":
+		err = checkGPGSigningKey(signingProgram("x509", "gpgsm"), status.Key)
+	default:
+		err = checkGPGSigningKey(signingProgram("openpgp", "gpg"), status.Key)
+	}
+	status.Available = err == nil
+	if err != nil {
+		status.Problem = err.Error()
+	}
+	return status
+}
+
+// signingStatusMsg carries the signing status checked in the background
+type signingStatusMsg struct {
+	status SigningStatus
+}
+
+// loadSigningStatus checks the signing setup off the UI goroutine
+func loadSigningStatus(gitService *GitService) tea.Cmd {
+	return func() tea.Msg {
+		return signingStatusMsg{status: gitService.GetSigningStatus()}
+	}
+}
+
+// signingProgram returns the program git signs with for a format
+func signingProgram(format, fallback string) string {
+	if program := gitConfigValue("gpg." + format + ".program"); program != "" {
+		return program
+	}
+	if format == "openpgp" {
+		if program := gitConfigValue("gpg.program"); program != "" {
+			return program
+		}
+	}
+	return fallback
+}
+
+// checkGPGSigningKey looks for a secret key, by default the one gpg would pick for the committer
+func checkGPGSigningKey(program, key string) error {
+	if key == "" {
+		output, err := gitCommand("var", "GIT_COMMITTER_IDENT").Output()
+		if err != nil {
+			return fmt.Errorf("no user.signingkey or committer email to sign with")
+		}
+		ident := string(output)
+		start, end := strings.Index(ident, "<"), strings.Index(ident, ">")
+		if start < 0 || end < start {
+			return fmt.Errorf("no user.signingkey or committer email to sign with")
+		}
+		key = ident[start+1 : end]
+	}
+
+	cmd := exec.Command(program, "--list-secret-keys", key)
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("%s is not installed", program)
+		}
+		return fmt.Errorf("%s has no secret key for %s", program, key)
+	}
+	return nil
+}
+
+// checkSSHSigningKey checks that an SSH signing key file exists, or that ssh-agent holds a
+// key given literally
+func checkSSHSigningKey(key string) error {
+	if key == "" {
+		if gitConfigValue("gpg.ssh.defaultKeyCommand") != "" {
+			return nil
+		}
+		return fmt.Errorf("user.signingkey is not set")
+	}
+
+	literal := strings.TrimPrefix(key, "key::")
+	if literal != key || strings.HasPrefix(key, "ssh-") {
+		output, err := exec.Command("ssh-add", "-L").Output()
+		if err != nil {
+			return fmt.Errorf("ssh-agent isn't running or has no keys")
+		}
+		fields := strings.Fields(literal)
+		for _, line := range strings.Split(string(output), "\n") {
+			agentKey := strings.Fields(line)
+			if len(fields) >= 2 && len(agentKey) >= 2 && agentKey[0] == fields[0] && agentKey[1] == fields[1] {
+				return nil
+			}
+		}
+		return fmt.Errorf("ssh-agent doesn't hold the signing key")
+	}
+
+	path := key
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("key file %s not found", key)
+	}
+	return nil
+}
+
+// signingFlag turns signing on or off for a commit, overriding commit.gpgsign
+func signingFlag(sign bool) string {
+	if sign {
+		return "--gpg-sign"
+	}
+	return "--no-gpg-sign"
+}
+
+// signatureMarker shows whether a commit's signature was verified, from its %G? status
+func signatureMarker(status string) string {
+	switch status {
+	case "G":
+		return signatureGoodStyle.Render("✓")
+	case "B":
+		return signatureBadStyle.Render("✗")
+	case "N", "":
+		return signatureNoneStyle.Render("·")
+	default:
+		return signatureUnverifiedStyle.Render("?")
+	}
+}
+
+// render describes whether the next commit will be signed and with which key
+func (s SigningStatus) render(sign bool) string {
+	if !sign {
+		if s.Enabled {
+			return signatureNoneStyle.Render("Signing: off for this commit (commit.gpgsign is on)")
+		}
+		return signatureNoneStyle.Render("Signing: off")
+	}
+
+	key := s.Key
+	if key == "" {
+		key = "default key"
+	}
+	if !s.Available {
+		return signatureUnverifiedStyle.Render(fmt.Sprintf("⚠ Signing: on, but %s", s.Problem))
+	}
+	return signatureGoodStyle.Render(fmt.Sprintf("✓ Signing: on with %s %s", s.Format, truncateString(key, 40)))
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestSignatureMarker(t *testing.T) {
+	tests := map[string]string{
+		"G": "✓", // Good signature
+		"N": "·", // Not signed
+		"":  "·", // Status not known
+		"B": "✗", // Bad signature
+		"U": "?", // Good signature from an untrusted key
+		"X": "?", // Expired signature
+		"Y": "?", // Expired key
+		"R": "?", // Revoked key
+		"E": "?", // Can't be checked
+	}
+	for status, want := range tests {
+		if got := ansi.Strip(signatureMarker(status)); got != want {
+			t.Errorf("signatureMarker(%q) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestSigningFlag(t *testing.T) {
+	if got := signingFlag(true); got != "--gpg-sign" {
+		t.Errorf("signingFlag(true) = %q", got)
+	}
+	if got := signingFlag(false); got != "--no-gpg-sign" {
+		t.Errorf("signingFlag(false) = %q", got)
+	}
+}
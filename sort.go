@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortKey is a field the branch list can be ordered by
+type SortKey int
+
+const (
+	SortLastUsed SortKey = iota
+	SortCommitDate
+	SortName
+	SortAuthor
+	SortAhead
+	SortFrecency
+)
+
+var sortKeyNames = []string{"last-used", "commit-date", "name", "author", "ahead", "frecency"}
+
+func (k SortKey) String() string {
+	if int(k) < len(sortKeyNames) {
+		return sortKeyNames[k]
+	}
+	return "unknown"
+}
+
+// defaultDescending reports whether a key reads best largest/newest first
+func (k SortKey) defaultDescending() bool {
+	return k != SortName && k != SortAuthor
+}
+
+// SortOrder is the sort key and direction of the branch list
+type SortOrder struct {
+	Key        SortKey
+	Descending bool
+}
+
+// DefaultSortOrder is the most recently used branches first
+var DefaultSortOrder = SortOrder{Key: SortLastUsed, Descending: true}
+
+// ParseSortOrder parses "key" or "key:asc" / "key:desc", e.g. "commit-date:asc"
+func ParseSortOrder(value string) (SortOrder, error) {
+	name, direction, hasDirection := strings.Cut(strings.TrimSpace(value), ":")
+
+	for i, keyName := range sortKeyNames {
+		if keyName != name {
+			continue
+		}
+		order := SortOrder{Key: SortKey(i), Descending: SortKey(i).defaultDescending()}
+		if hasDirection {
+			switch direction {
+			case "asc":
+				order.Descending = false
+			case "desc":
+				order.Descending = true
+			default:
+				return DefaultSortOrder, fmt.Errorf("invalid sort direction %q (expected asc or desc)", direction)
+			}
+		}
+		return order, nil
+	}
+
+	return DefaultSortOrder, fmt.Errorf("unknown sort key %q (expected one of %s)", name, strings.Join(sortKeyNames, ", "))
+}
+
+func (o SortOrder) String() string {
+	if o.Descending {
+		return o.Key.String() + ":desc"
+	}
+	return o.Key.String() + ":asc"
+}
+
+// Label is the short form shown in the title, e.g. "name ↑"
+func (o SortOrder) Label() string {
+	if o.Descending {
+		return o.Key.String() + " ↓"
+	}
+	return o.Key.String() + " ↑"
+}
+
+// Next moves on to the next sort key in its natural direction
+func (o SortOrder) Next() SortOrder {
+	key := SortKey((int(o.Key) + 1) % len(sortKeyNames))
+	return SortOrder{Key: key, Descending: key.defaultDescending()}
+}
+
+// Reversed flips the sort direction
+func (o SortOrder) Reversed() SortOrder {
+	o.Descending = !o.Descending
+	return o
+}
+
+// less reports whether a sorts before b, ignoring direction
+func (o SortOrder) less(a, b Branch) bool {
+	switch o.Key {
+	case SortCommitDate:
+		return a.CommitDate.Before(b.CommitDate)
+	case SortName:
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	case SortAuthor:
+		return strings.ToLower(a.Author) < strings.ToLower(b.Author)
+	case SortAhead:
+		return a.AheadCount < b.AheadCount
+	case SortFrecency:
+		return a.Frecency < b.Frecency
+	default:
+		return a.LastUsed.Before(b.LastUsed)
+	}
+}
+
+// before reports whether a is listed before b, falling back to most recently used on ties
+func (o SortOrder) before(a, b Branch) bool {
+	if o.less(a, b) == o.less(b, a) {
+		return a.LastUsed.After(b.LastUsed)
+	}
+	if o.Descending {
+		return o.less(b, a)
+	}
+	return o.less(a, b)
+}
+
+// sortBranches orders branches in place
+func sortBranches(branches []Branch, order SortOrder) {
+	sort.SliceStable(branches, func(i, j int) bool {
+		return order.before(branches[i], branches[j])
+	})
+}
+
+// frecencyWeight scores a single checkout by how long ago it happened
+func frecencyWeight(age time.Duration) float64 {
+	switch {
+	case age < 4*24*time.Hour:
+		return 100
+	case age < 14*24*time.Hour:
+		return 70
+	case age < 31*24*time.Hour:
+		return 50
+	case age < 90*24*time.Hour:
+		return 30
+	default:
+		return 10
+	}
+}
+
+// fillAheadBehind counts the commits each branch has on top of, and is missing from, its base branch
+func (g *GitService) fillAheadBehind(branches []Branch) {
+	for i, branch := range branches {
+		ref := gitRefForBranch(branch.Name)
+		base, mergeBase, err := g.findBaseBranch(ref)
+		if err != nil {
+			continue
+		}
+		if base == "root commit" {
+			base = mergeBase
+		}
+
+		cmd := gitCommand("rev-list", "--left-right", "--count", base+"..."+ref)
+		output, err := cmd.Output()
+		if err != nil {
+			continue
+		}
+		counts := strings.Fields(string(output))
+		if len(counts) == 2 {
+			branches[i].BehindCount = parseInt(counts[0])
+			branches[i].AheadCount = parseInt(counts[1])
+		}
+	}
+}
+
+// savedSortOrder is how the sort order is persisted between sessions
+type savedSortOrder struct {
+	Sort string `json:"sort"`
+}
+
+// LoadSortOrder returns the sort order saved for this repository, or the default
+func (g *GitService) LoadSortOrder() SortOrder {
+	path, err := g.repoStatePath("sort.json")
+	if err != nil {
+		return DefaultSortOrder
+	}
+
+	var saved savedSortOrder
+	if err := loadJSONFile(path, &saved); err != nil || saved.Sort == "" {
+		return DefaultSortOrder
+	}
+
+	order, err := ParseSortOrder(saved.Sort)
+	if err != nil {
+		return DefaultSortOrder
+	}
+	return order
+}
+
+// SaveSortOrder persists the sort order for this repository
+func (g *GitService) SaveSortOrder(order SortOrder) error {
+	path, err := g.repoStatePath("sort.json")
+	if err != nil {
+		return err
+	}
+	return saveJSONFile(path, savedSortOrder{Sort: order.String()})
+}
+
+// changeSortOrder re-sorts the branch list and remembers the choice
+func (m *model) changeSortOrder(order SortOrder) {
+	m.sortOrder = order
+	m.tableManager.sortOrder = order
+	if err := m.gitService.SaveSortOrder(order); err != nil {
+		m.logError("Failed to save sort order: %v", err)
+	}
+
+	m.logInfo("Sorting branches by %s", order.Label())
+	if err := m.loadBranches(); err != nil {
+		m.logError("Failed to reload branches: %v", err)
+		m.message = fmt.Sprintf("Error: %v", err)
+		return
+	}
+	m.setupTable()
+	m.message = fmt.Sprintf("Sorted by %s", order.Label())
+}
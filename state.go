@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// repoStatePath returns the path of a state file kept inside the repository's git directory,
+// so it is per-repo, shared by all worktrees, and never shows up as an untracked file
+func (g *GitService) repoStatePath(name string) (string, error) {
+	cmd := gitCommand("rev-parse", "--git-common-dir")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git directory: %v", err)
+	}
+
+	gitDir, err := filepath.Abs(strings.TrimSpace(string(output)))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, "recent-branches", name), nil
+}
+
+// loadJSONFile decodes a JSON file into v, leaving v untouched if the file doesn't exist yet
+func loadJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return nil
+}
+
+// saveJSONFile writes v as indented JSON, replacing the file atomically
+func saveJSONFile(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -9,88 +13,294 @@ import (
 var (
 	// Table styles
 	tableStyle = lipgloss.NewStyle().
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("240"))
+		BorderStyle(lipgloss.NormalBorder())
 )
 
 type TableManager struct {
-	table table.Model
+	table     table.Model // Draws the rows in view, from offset on
+	rows      []tableRow
+	cells     []table.Row // Rendered rows, all of them
+	cursor    int
+	offset    int             // First row in view
+	treeMode  bool            // Group branches by "/" prefix
+	collapsed map[string]bool // Collapsed group paths in tree mode
+	branches  []Branch
+	columns   []*branchColumn // Configured columns
+	visible   []*branchColumn // Columns that fit the terminal width
+	width     int
+	height    int       // Table height from the layout, 0 to size to the rows
+	sortOrder SortOrder // Orders the groups and branches of the tree
+
+	loadedColumns map[string]bool // Columns whose data was loaded for the current branches
 }
 
-func NewTableManager() *TableManager {
-	return &TableManager{}
+func NewTableManager(columns []*branchColumn, sortOrder SortOrder) *TableManager {
+	return &TableManager{
+		collapsed:     make(map[string]bool),
+		loadedColumns: make(map[string]bool),
+		columns:       columns,
+		sortOrder:     sortOrder,
+	}
 }
 
+// SetupTable rebuilds the rows from branches with the cursor back on the first row
 func (tm *TableManager) SetupTable(branches []Branch) {
-	columns := []table.Column{
-		{Title: "Branch", Width: 35},
-		{Title: "Last Used", Width: 15},
-		{Title: "Last Commit", Width: 12},
-		{Title: "Commit Message", Width: 60},
-	}
+	tm.cursor, tm.offset = 0, 0
+	tm.rebuild(branches)
+}
 
-	rows := make([]table.Row, 0, len(branches))
-	for _, branch := range branches {
-		// Update relative times
-		branch.RelativeTime = formatLastUsedTime(branch.LastUsed)
+// rebuild renders the rows from branches, keeping the cursor and scroll position where possible
+func (tm *TableManager) rebuild(branches []Branch) {
+	tm.branches = branches
+	var columns []table.Column
+	tm.visible, columns = fitColumns(tm.columns, tm.width)
 
-		commitMsg := truncateString(branch.CommitTitle, 57)
-		commitDate := branch.CommitDate.Format("2006-01-02")
+	if tm.treeMode {
+		tm.rows = buildBranchTree(branches, tm.sortOrder).flatten(tm.collapsed, 0)
+	} else {
+		tm.rows = make([]tableRow, 0, len(branches))
+		for i := range branches {
+			tm.rows = append(tm.rows, tableRow{branch: &branches[i]})
+		}
+	}
 
-		// Add current branch indicator
-		branchName := branch.Name
-		if branch.RelativeTime == "active now" {
-			branchName = "* " + branch.Name // Add asterisk for current branch
+	rows := make([]table.Row, 0, len(tm.rows))
+	for _, row := range tm.rows {
+		indent := strings.Repeat("  ", row.depth)
+		if row.group != nil {
+			rows = append(rows, tm.groupRow(row.group, indent, tm.collapsed[row.group.Path]))
+			continue
 		}
 
-		row := table.Row{
-			branchName,
-			branch.RelativeTime,
-			commitDate,
-			commitMsg,
+		name := row.branch.Name
+		if tm.treeMode {
+			name = name[strings.LastIndex(branchPath(*row.branch), "/")+1:]
 		}
-		rows = append(rows, row)
+		rows = append(rows, tm.branchRow(*row.branch, indent, name))
 	}
 
 	// Ensure we have at least one row to avoid empty table issues
 	if len(rows) == 0 {
-		rows = append(rows, table.Row{"No branches found", "", "", ""})
+		empty := make(table.Row, len(columns))
+		empty[0] = "No branches found"
+		rows = append(rows, empty)
 	}
 
+	tm.cells = rows
 	t := table.New(
 		table.WithColumns(columns),
-		table.WithRows(rows),
 		table.WithFocused(true),
-		table.WithHeight(min(len(rows)+1, 20)), // Limit max height
+		table.WithHeight(tm.tableHeight(len(rows))),
+		table.WithKeyMap(mainKeys.tableKeyMap()),
 	)
 
+	t.SetStyles(tableStyles())
+	tm.table = t
+	tm.scroll()
+}
+
+// scroll keeps the cursor in view and hands the table the rows it shows. The table only ever
+// gets one screen of rows, so it never scrolls by itself and the offset stays known.
+func (tm *TableManager) scroll() {
+	height := max(tm.table.Height(), 1)
+	tm.cursor = min(max(tm.cursor, 0), max(len(tm.cells)-1, 0))
+	tm.offset = min(max(tm.offset, tm.cursor-height+1), tm.cursor)
+	tm.offset = min(max(tm.offset, 0), max(len(tm.cells)-height, 0))
+
+	tm.table.SetRows(tm.cells[tm.offset:min(tm.offset+height, len(tm.cells))])
+	tm.table.SetCursor(tm.cursor - tm.offset)
+}
+
+// SetCursor selects a row, scrolling it into view
+func (tm *TableManager) SetCursor(cursor int) {
+	tm.cursor = cursor
+	tm.scroll()
+}
+
+// MoveCursor moves the selection by delta rows, stopping at the first and last row
+func (tm *TableManager) MoveCursor(delta int) {
+	tm.SetCursor(tm.cursor + delta)
+}
+
+// tableHeight is the layout height, or the row count capped at 20 before the terminal size is known
+func (tm *TableManager) tableHeight(rows int) int {
+	if tm.height > 0 {
+		return tm.height
+	}
+	return min(rows+1, 20)
+}
+
+// SetWidth refits the columns to a new terminal width, keeping the cursor
+func (tm *TableManager) SetWidth(width int) {
+	if width == tm.width {
+		return
+	}
+	tm.width = width
+	tm.rebuild(tm.branches)
+}
+
+// SetHeight sets the number of lines the table takes, header included
+func (tm *TableManager) SetHeight(height int) {
+	tm.height = height
+	tm.table.SetHeight(tm.tableHeight(len(tm.cells)))
+	tm.scroll()
+}
+
+// tableStyles returns the header and selection styles shared by the branch and tag tables
+func tableStyles() table.Styles {
 	s := table.DefaultStyles()
 	s.Header = s.Header.
 		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("240")).
+		BorderForeground(lipgloss.Color(theme.Border)).
 		BorderBottom(true).
 		Bold(false).
-		Foreground(lipgloss.Color("229"))
+		Foreground(lipgloss.Color(theme.SelectedFg))
 
 	s.Selected = s.Selected.
-		Foreground(lipgloss.Color("229")).
-		Background(lipgloss.Color("57")).
+		Foreground(lipgloss.Color(theme.SelectedFg)).
+		Background(lipgloss.Color(theme.SelectedBg)).
+		Reverse(theme.Reverse).
 		Bold(false)
 
-	t.SetStyles(s)
-	tm.table = t
+	return s
+}
+
+// branchRow renders a branch with its current, conflict and remote update markers
+func (tm *TableManager) branchRow(branch Branch, indent, branchName string) table.Row {
+	// Add current branch indicator
+	if formatLastUsedTime(branch.LastUsed) == "active now" {
+		branchName = "* " + branchName // Add asterisk for current branch
+	}
+
+	// Flag branches where switching or merging is predicted to conflict
+	branchName = branch.Prediction.Badge() + branchName
+
+	// Highlight branches others pushed to since the last background fetch
+	if len(branch.UpdatedBy) > 0 {
+		branchName = "↓ " + branchName
+	}
+
+	row := make(table.Row, 0, len(tm.visible))
+	for _, column := range tm.visible {
+		row = append(row, column.value(branch, indent+branchName))
+	}
+	return row
+}
+
+// groupRow renders a tree group header with its branch count and most recent activity
+func (tm *TableManager) groupRow(group *BranchTreeNode, indent string, collapsed bool) table.Row {
+	icon := "▼"
+	if collapsed {
+		icon = "▶"
+	}
+
+	row := make(table.Row, 0, len(tm.visible))
+	for _, column := range tm.visible {
+		switch {
+		case column.Name == "branch":
+			row = append(row, fmt.Sprintf("%s%s %s/ (%d)", indent, icon, group.Name, group.Count))
+		case column.group != nil:
+			row = append(row, column.group(group))
+		default:
+			row = append(row, "")
+		}
+	}
+	return row
+}
+
+// RefreshRows rebuilds the rows from updated branches, keeping the cursor on the same branch
+func (tm *TableManager) RefreshRows(branches []Branch) {
+	var selected string
+	if branch := tm.SelectedBranch(); branch != nil {
+		selected = branch.Name
+	}
+
+	tm.rebuild(branches)
+	for i, row := range tm.rows {
+		if row.branch != nil && row.branch.Name == selected {
+			tm.SetCursor(i)
+			break
+		}
+	}
+}
+
+// ToggleTreeMode switches between the flat list and the prefix tree
+func (tm *TableManager) ToggleTreeMode() {
+	tm.treeMode = !tm.treeMode
+	tm.SetupTable(tm.branches)
+}
+
+func (tm *TableManager) IsTreeMode() bool {
+	return tm.treeMode
+}
+
+// SelectedBranch returns the branch under the cursor, or nil on a group row
+func (tm *TableManager) SelectedBranch() *Branch {
+	cursor := tm.cursor
+	if cursor < 0 || cursor >= len(tm.rows) {
+		return nil
+	}
+	return tm.rows[cursor].branch
+}
+
+// ToggleSelectedGroup collapses or expands the group under the cursor and reports whether there was one
+func (tm *TableManager) ToggleSelectedGroup() bool {
+	cursor := tm.cursor
+	if cursor < 0 || cursor >= len(tm.rows) || tm.rows[cursor].group == nil {
+		return false
+	}
+
+	path := tm.rows[cursor].group.Path
+	tm.collapsed[path] = !tm.collapsed[path]
+	tm.rebuild(tm.branches)
+	return true
 }
 
 func (tm *TableManager) GetTable() table.Model {
 	return tm.table
 }
 
-func (tm *TableManager) UpdateTable(msg interface{}) (table.Model, tea.Cmd) {
-	return tm.table.Update(msg)
+// Update moves the cursor with the table's navigation keys
+func (tm *TableManager) Update(msg tea.Msg) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return
+	}
+
+	keys, height := tm.table.KeyMap, tm.table.Height()
+	switch {
+	case key.Matches(keyMsg, keys.LineUp):
+		tm.MoveCursor(-1)
+	case key.Matches(keyMsg, keys.LineDown):
+		tm.MoveCursor(1)
+	case key.Matches(keyMsg, keys.PageUp):
+		tm.MoveCursor(-height)
+	case key.Matches(keyMsg, keys.PageDown):
+		tm.MoveCursor(height)
+	case key.Matches(keyMsg, keys.HalfPageUp):
+		tm.MoveCursor(-height / 2)
+	case key.Matches(keyMsg, keys.HalfPageDown):
+		tm.MoveCursor(height / 2)
+	case key.Matches(keyMsg, keys.GotoTop):
+		tm.SetCursor(0)
+	case key.Matches(keyMsg, keys.GotoBottom):
+		tm.SetCursor(len(tm.cells) - 1)
+	}
 }
 
 func (tm *TableManager) GetCursor() int {
-	return tm.table.Cursor()
+	return tm.cursor
+}
+
+// rowAt returns the row drawn on a line of the table view (border excluded), or -1 for the
+// header and empty lines
+func (tm *TableManager) rowAt(line int) int {
+	header := lipgloss.Height(tm.table.View()) - tm.table.Height()
+	row := tm.offset + line - header
+	if line < header || line >= header+tm.table.Height() || row >= len(tm.rows) {
+		return -1
+	}
+	return row
 }
 
 func (tm *TableManager) View() string {
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTableRowAtFollowsScrolling(t *testing.T) {
+	var branches []Branch
+	for i := range 10 {
+		branches = append(branches, Branch{Name: fmt.Sprintf("branch-%d", i)})
+	}
+	tm := NewTableManager(columnRegistry(DefaultConfig)[:1], DefaultSortOrder)
+	tm.SetWidth(40)
+	tm.SetupTable(branches)
+	tm.SetHeight(5) // Header, its border and three rows
+
+	header := 2
+	tm.SetCursor(9)
+	if got := tm.rowAt(header); got != 7 {
+		t.Errorf("top line at the bottom of the list: row %d, want 7", got)
+	}
+
+	// Moving up inside the screen doesn't scroll
+	tm.MoveCursor(-2)
+	if got := tm.rowAt(header); got != 7 {
+		t.Errorf("top line after moving up: row %d, want 7", got)
+	}
+
+	// Moving above the screen scrolls so the cursor is on the first line
+	tm.MoveCursor(-3)
+	if got := tm.rowAt(header); got != 4 {
+		t.Errorf("top line after scrolling up: row %d, want 4", got)
+	}
+	if got := tm.rowAt(header + 2); got != 6 {
+		t.Errorf("bottom line after scrolling up: row %d, want 6", got)
+	}
+	if got := tm.rowAt(0); got != -1 {
+		t.Errorf("header line: row %d, want -1", got)
+	}
+}
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ViewTab is a top-level tab of the main screen
+type ViewTab int
+
+const (
+	TabBranches ViewTab = iota
+	TabTags
+)
+
+// Tag is an annotated or lightweight tag
+type Tag struct {
+	Name                 string
+	Hash                 string // Commit the tag points at
+	Annotated            bool
+	Date                 time.Time
+	Tagger               string // Tagger for annotated tags, commit author for lightweight ones
+	Message              string
+	CommitsSincePrevious int // Commits between the previous (older) tag and this one
+}
+
+// GetRecentTags returns the most recent tags, newest first, with the commit count since the tag before each
+func (g *GitService) GetRecentTags(count int) ([]Tag, error) {
+	cmd := gitCommand("for-each-ref",
+		"--sort=-creatordate",
+		"--format=%(refname:short)|%(objecttype)|%(if)%(*objectname)%(then)%(*objectname)%(else)%(objectname)%(end)|%(creatordate:iso8601)|%(if)%(taggername)%(then)%(taggername)%(else)%(authorname)%(end)|%(contents:subject)",
+		"refs/tags/")
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git tags: %v", err)
+	}
+
+	var tags []Tag
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "|", 6)
+		if len(parts) != 6 {
+			continue
+		}
+
+		date, err := parseGitDate(strings.TrimSpace(parts[3]))
+		if err != nil {
+			date = time.Time{}
+		}
+
+		tags = append(tags, Tag{
+			Name:      parts[0],
+			Annotated: parts[1] == "tag",
+			Hash:      parts[2],
+			Date:      date,
+			Tagger:    parts[4],
+			Message:   strings.TrimSpace(parts[5]),
+		})
+	}
+
+	// Count one more tag than shown so the oldest listed one still has a predecessor
+	for i := 0; i < len(tags) && i < count; i++ {
+		revRange := tags[i].Hash
+		if i+1 < len(tags) {
+			revRange = tags[i+1].Hash + ".." + tags[i].Hash
+		}
+		if n, err := g.countRevisions(revRange); err == nil {
+			tags[i].CommitsSincePrevious = n
+		}
+	}
+
+	if len(tags) > count {
+		tags = tags[:count]
+	}
+	return tags, nil
+}
+
+// countRevisions counts the commits in a revision range
+func (g *GitService) countRevisions(revRange string) (int, error) {
+	cmd := gitCommand("rev-list", "--count", revRange)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, err
+	}
+	return parseInt(strings.TrimSpace(string(output))), nil
+}
+
+// CheckoutTag checks out a tag as a detached HEAD
+func (g *GitService) CheckoutTag(tagName string) error {
+	cmd := gitCommand("checkout", "--detach", "refs/tags/"+tagName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to checkout tag %s: %v\nOutput: %s", tagName, err, string(output))
+	}
+	return nil
+}
+
+// CreateBranchFromTag creates a branch at a tag and switches to it
+func (g *GitService) CreateBranchFromTag(branchName, tagName string) error {
+	from, _ := g.GetCurrentBranch()
+	cmd := gitCommand("checkout", "-b", branchName, "refs/tags/"+tagName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s from tag %s: %v\nOutput: %s", branchName, tagName, err, string(output))
+	}
+
+	g.recordNavigation(from, branchName)
+	return nil
+}
+
+// TagAction is an action requested from the tags view that the main model carries out
+type TagAction int
+
+const (
+	TagActionNone TagAction = iota
+	TagActionCheckout
+	TagActionCreateBranch
+)
+
+// TagsView lists recent tags and lets the user check one out or branch from it
+type TagsView struct {
+	table       table.Model
+	tags        []Tag
+	creating    bool // Entering a name for a branch created from the selected tag
+	branchInput textinput.Model
+	action      TagAction
+	keys        TagsKeyMap
+	height      int // Table height from the layout, 0 to size to the rows
+}
+
+type TagsKeyMap struct {
+	Checkout     key.Binding
+	CreateBranch key.Binding
+	Confirm      key.Binding
+	Cancel       key.Binding
+}
+
+var tagsKeys = TagsKeyMap{
+	Checkout: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "checkout (detached)"),
+	),
+	CreateBranch: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "branch from tag"),
+	),
+	Confirm: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "create & switch"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel"),
+	),
+}
+
+func (k *TagsKeyMap) named() []namedBinding {
+	return []namedBinding{
+		{"tags.checkout", &k.Checkout},
+		{"tags.create-branch", &k.CreateBranch},
+		{"tags.confirm", &k.Confirm},
+		{"tags.cancel", &k.Cancel},
+	}
+}
+
+var (
+	tabActiveStyle = lipgloss.NewStyle().
+			Padding(0, 1)
+
+	tabInactiveStyle = lipgloss.NewStyle().
+				Padding(0, 1)
+
+	detachedWarningStyle = lipgloss.NewStyle().
+				Bold(true).
+				Padding(0, 1)
+)
+
+func NewTagsView() *TagsView {
+	input := textinput.New()
+	input.Placeholder = "new branch name"
+	input.CharLimit = 100
+	input.Width = 40
+
+	return &TagsView{
+		branchInput: input,
+		keys:        tagsKeys,
+	}
+}
+
+// SetTags replaces the listed tags
+func (v *TagsView) SetTags(tags []Tag) {
+	v.tags = tags
+
+	columns := []table.Column{
+		{Title: "Tag", Width: 25},
+		{Title: "Date", Width: 12},
+		{Title: "Tagger", Width: 18},
+		{Title: "Commits", Width: 8},
+		{Title: "Message", Width: 55},
+	}
+
+	rows := make([]table.Row, 0, len(tags))
+	for _, tag := range tags {
+		name := tag.Name
+		if !tag.Annotated {
+			name += " (light)"
+		}
+		rows = append(rows, table.Row{
+			name,
+			tag.Date.Format("2006-01-02"),
+			truncateString(tag.Tagger, 18),
+			fmt.Sprintf("+%d", tag.CommitsSincePrevious),
+			truncateString(tag.Message, 52),
+		})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No tags found", "", "", "", ""})
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(v.tableHeight(len(rows))),
+		table.WithKeyMap(mainKeys.tableKeyMap()),
+	)
+
+	t.SetStyles(tableStyles())
+
+	v.table = t
+}
+
+func (v *TagsView) tableHeight(rows int) int {
+	switch {
+	case v.height > 0 && v.creating:
+		return max(v.height-4, minTableHeight) // Leave room for the branch name prompt
+	case v.height > 0:
+		return v.height
+	}
+	return min(rows+1, 20)
+}
+
+// SetHeight sets the number of lines the tag table takes, header included
+func (v *TagsView) SetHeight(height int) {
+	v.height = height
+	v.table.SetHeight(v.tableHeight(len(v.table.Rows())))
+}
+
+// SelectedTag returns the tag under the cursor, if any
+func (v *TagsView) SelectedTag() (Tag, bool) {
+	cursor := v.table.Cursor()
+	if cursor < 0 || cursor >= len(v.tags) {
+		return Tag{}, false
+	}
+	return v.tags[cursor], true
+}
+
+// IsEditing reports whether the branch name input has focus
+func (v *TagsView) IsEditing() bool {
+	return v.creating
+}
+
+func (v *TagsView) GetAction() TagAction {
+	return v.action
+}
+
+func (v *TagsView) ClearAction() {
+	v.action = TagActionNone
+}
+
+// GetBranchName returns the name entered for a branch created from a tag
+func (v *TagsView) GetBranchName() string {
+	return strings.TrimSpace(v.branchInput.Value())
+}
+
+func (v *TagsView) Update(msg tea.Msg) (*TagsView, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+
+	if v.creating {
+		if ok {
+			switch {
+			case key.Matches(keyMsg, v.keys.Cancel):
+				v.creating = false
+				v.branchInput.Blur()
+				return v, nil
+			case key.Matches(keyMsg, v.keys.Confirm):
+				if v.GetBranchName() != "" {
+					v.creating = false
+					v.branchInput.Blur()
+					v.action = TagActionCreateBranch
+				}
+				return v, nil
+			}
+		}
+		var cmd tea.Cmd
+		v.branchInput, cmd = v.branchInput.Update(msg)
+		return v, cmd
+	}
+
+	if ok {
+		switch {
+		case key.Matches(keyMsg, v.keys.Checkout):
+			if _, found := v.SelectedTag(); found {
+				v.action = TagActionCheckout
+			}
+			return v, nil
+		case key.Matches(keyMsg, v.keys.CreateBranch):
+			if tag, found := v.SelectedTag(); found {
+				v.creating = true
+				v.branchInput.SetValue("release/" + tag.Name)
+				v.branchInput.CursorEnd()
+				v.branchInput.Focus()
+			}
+			return v, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	v.table, cmd = v.table.Update(msg)
+	return v, cmd
+}
+
+func (v *TagsView) View() string {
+	view := tableStyle.Render(v.table.View())
+	if v.creating {
+		tag, _ := v.SelectedTag()
+		prompt := labelStyle.Render(fmt.Sprintf("Create branch from %s:", tag.Name))
+		view = lipgloss.JoinVertical(lipgloss.Left, view, "", prompt, v.branchInput.View(),
+			helpStyle.Render(shortHelp(v.keys.Confirm, v.keys.Cancel)))
+	}
+	return view
+}
+
+// renderTabs renders the tab bar above the main table
+func renderTabs(active ViewTab) string {
+	tabs := []string{"1: Branches", "2: Tags"}
+	var rendered []string
+	for i, tab := range tabs {
+		if ViewTab(i) == active {
+			rendered = append(rendered, tabActiveStyle.Render(tab))
+		} else {
+			rendered = append(rendered, tabInactiveStyle.Render(tab))
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+}
+
+// loadTags refreshes the tags tab
+func (m *model) loadTags() {
+	tags, err := m.gitService.GetRecentTags(m.count)
+	if err != nil {
+		m.logError("Failed to load tags: %v", err)
+		tags = nil
+	}
+	m.tagsView.SetTags(tags)
+	m.logDebug("Loaded %d tags", len(tags))
+}
+
+// handleTagAction carries out a checkout or branch creation requested in the tags tab
+func (m *model) handleTagAction() {
+	action := m.tagsView.GetAction()
+	if action == TagActionNone {
+		return
+	}
+	m.tagsView.ClearAction()
+
+	tag, ok := m.tagsView.SelectedTag()
+	if !ok {
+		return
+	}
+
+	if m.worktreeBusy("check out a tag") {
+		return
+	}
+
+	switch action {
+	case TagActionCheckout:
+		hasChanges, err := m.gitService.HasUncommittedChanges()
+		if err != nil {
+			m.logError("Failed to check for uncommitted changes: %v", err)
+			m.message = fmt.Sprintf("Error: %v", err)
+			return
+		}
+		if hasChanges {
+			m.logInfo("Found uncommitted changes, showing commit modal")
+			m.pendingTag = tag.Name
+			m.commitModal.Show(tag.Name, nil)
+			return
+		}
+
+		m.logInfo("Checking out tag %s as detached HEAD", tag.Name)
+		if err := m.gitService.CheckoutTag(tag.Name); err != nil {
+			m.logError("%v", err)
+			m.message = fmt.Sprintf("Error: %v", err)
+			return
+		}
+		m.logInfo("HEAD is now detached at %s; new commits won't belong to any branch", tag.Name)
+		m.message = fmt.Sprintf("Checked out tag %s (detached HEAD)", tag.Name)
+
+	case TagActionCreateBranch:
+		branchName := m.tagsView.GetBranchName()
+		m.logInfo("Creating branch %s from tag %s", branchName, tag.Name)
+		if err := m.gitService.CreateBranchFromTag(branchName, tag.Name); err != nil {
+			m.logError("%v", err)
+			m.message = fmt.Sprintf("Error: %v", err)
+			return
+		}
+		m.logSuccess("Created and switched to branch %s", branchName)
+		m.message = fmt.Sprintf("Created branch %s from tag %s", branchName, tag.Name)
+	}
+
+	m.refreshAfterOperation()
+}
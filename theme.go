@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Theme is a palette of semantic colors. Colors are ANSI numbers ("39") or hex ("#5f87ff");
+// an empty color means the terminal default.
+type Theme struct {
+	Base       string `json:"base,omitempty"`      // Built-in theme a user theme inherits unset colors from
+	Accent     string `json:"accent,omitempty"`    // Titles
+	Primary    string `json:"primary,omitempty"`   // Focused borders, hashes, info
+	Muted      string `json:"muted,omitempty"`     // Help text and timestamps
+	Border     string `json:"border,omitempty"`    // Unfocused borders and debug logs
+	Success    string `json:"success,omitempty"`   // Success messages and added lines
+	Error      string `json:"error,omitempty"`     // Errors and deleted lines
+	Warning    string `json:"warning,omitempty"`   // Conflict and detached HEAD warnings
+	Highlight  string `json:"highlight,omitempty"` // Authors, diff headers, operation banner
+	SelectedFg string `json:"selected_fg,omitempty"`
+	SelectedBg string `json:"selected_bg,omitempty"`
+	ButtonFg   string `json:"button_fg,omitempty"`
+	Reverse    bool   `json:"reverse,omitempty"` // Mark selection with reverse video instead of color
+}
+
+var builtinThemes = map[string]Theme{
+	"dark": {
+		Accent: "205", Primary: "39", Muted: "241", Border: "240",
+		Success: "42", Error: "196", Warning: "214", Highlight: "226",
+		SelectedFg: "229", SelectedBg: "57", ButtonFg: "15",
+	},
+	"light": {
+		Accent: "161", Primary: "25", Muted: "243", Border: "250",
+		Success: "28", Error: "160", Warning: "166", Highlight: "130",
+		SelectedFg: "231", SelectedBg: "25", ButtonFg: "231",
+	},
+	"high-contrast": {
+		Accent: "13", Primary: "14", Muted: "7", Border: "15",
+		Success: "10", Error: "9", Warning: "11", Highlight: "11",
+		SelectedFg: "0", SelectedBg: "15", ButtonFg: "0",
+	},
+	"mono": {
+		Reverse: true,
+	},
+}
+
+// theme is the palette the styles were last built from
+var theme = builtinThemes["dark"]
+
+// resolveTheme picks a theme by name. "auto" chooses dark or light from the terminal
+// background, and NO_COLOR or a terminal without colors always gets the mono theme.
+func resolveTheme(name string, userThemes map[string]Theme) (Theme, error) {
+	if _, noColor := os.LookupEnv("NO_COLOR"); noColor || lipgloss.ColorProfile() == termenv.Ascii {
+		return builtinThemes["mono"], nil
+	}
+
+	if name == "" || name == "auto" {
+		name = "light"
+		if lipgloss.HasDarkBackground() {
+			name = "dark"
+		}
+	}
+
+	if t, ok := userThemes[name]; ok {
+		if t.Base == "" {
+			t.Base = "dark"
+		}
+		base, ok := builtinThemes[t.Base]
+		if !ok {
+			return theme, fmt.Errorf("theme %q has unknown base %q", name, t.Base)
+		}
+		return t.inherit(base), nil
+	}
+
+	if t, ok := builtinThemes[name]; ok {
+		return t, nil
+	}
+
+	var names []string
+	for builtin := range builtinThemes {
+		names = append(names, builtin)
+	}
+	for custom := range userThemes {
+		names = append(names, custom)
+	}
+	sort.Strings(names)
+	return theme, fmt.Errorf("unknown theme %q (expected auto, %s)", name, strings.Join(names, ", "))
+}
+
+// inherit fills in colors a user theme leaves unset from its base
+func (t Theme) inherit(base Theme) Theme {
+	fill := func(color *string, fallback string) {
+		if *color == "" {
+			*color = fallback
+		}
+	}
+	fill(&t.Accent, base.Accent)
+	fill(&t.Primary, base.Primary)
+	fill(&t.Muted, base.Muted)
+	fill(&t.Border, base.Border)
+	fill(&t.Success, base.Success)
+	fill(&t.Error, base.Error)
+	fill(&t.Warning, base.Warning)
+	fill(&t.Highlight, base.Highlight)
+	fill(&t.SelectedFg, base.SelectedFg)
+	fill(&t.SelectedBg, base.SelectedBg)
+	fill(&t.ButtonFg, base.ButtonFg)
+	t.Reverse = t.Reverse || base.Reverse
+	return t
+}
+
+// applyTheme colors every style from the palette
+func applyTheme(t Theme) {
+	theme = t
+	accent := lipgloss.Color(t.Accent)
+	primary := lipgloss.Color(t.Primary)
+	muted := lipgloss.Color(t.Muted)
+	border := lipgloss.Color(t.Border)
+	success := lipgloss.Color(t.Success)
+	failure := lipgloss.Color(t.Error)
+	warning := lipgloss.Color(t.Warning)
+	highlight := lipgloss.Color(t.Highlight)
+	selectedFg := lipgloss.Color(t.SelectedFg)
+	selectedBg := lipgloss.Color(t.SelectedBg)
+	buttonFg := lipgloss.Color(t.ButtonFg)
+
+	// Main screen
+	titleStyle = titleStyle.Foreground(accent)
+	helpStyle = helpStyle.Foreground(muted)
+	errorStyle = errorStyle.Foreground(failure)
+	successStyle = successStyle.Foreground(success)
+	logContainerStyle = logContainerStyle.BorderForeground(border)
+	logFocusedStyle = logFocusedStyle.BorderForeground(primary)
+	logTitleStyle = logTitleStyle.Foreground(accent)
+	debugStyle = debugStyle.Foreground(border)
+	infoStyle = infoStyle.Foreground(primary)
+	logErrorStyle = logErrorStyle.Foreground(failure)
+	logSuccessStyle = logSuccessStyle.Foreground(success)
+	timestampStyle = timestampStyle.Foreground(muted)
+	logMatchStyle = logMatchStyle.Foreground(selectedFg).Background(selectedBg).Reverse(t.Reverse)
+	logDetailStyle = logDetailStyle.BorderForeground(primary)
+	commitContainerStyle = commitContainerStyle.BorderForeground(border)
+	commitFocusedStyle = commitFocusedStyle.BorderForeground(primary)
+	commitTitleStyle = commitTitleStyle.Foreground(accent)
+	commitHashStyle = commitHashStyle.Foreground(primary)
+	commitAuthorStyle = commitAuthorStyle.Foreground(highlight)
+	commitTimeStyle = commitTimeStyle.Foreground(muted)
+	tableStyle = tableStyle.BorderForeground(border)
+	tabActiveStyle = tabActiveStyle.Foreground(selectedFg).Background(selectedBg).Reverse(t.Reverse)
+	tabInactiveStyle = tabInactiveStyle.Foreground(muted)
+	detachedWarningStyle = detachedWarningStyle.Foreground(warning)
+	operationBannerStyle = operationBannerStyle.Foreground(highlight)
+	predictionWarningStyle = predictionWarningStyle.Foreground(warning)
+
+	// Preview and commit log
+	diffContainerStyle = diffContainerStyle.BorderForeground(border)
+	diffFocusedStyle = diffFocusedStyle.BorderForeground(primary)
+	diffAddStyle = diffAddStyle.Foreground(success)
+	diffDelStyle = diffDelStyle.Foreground(failure)
+	diffHunkStyle = diffHunkStyle.Foreground(primary)
+	diffMetaStyle = diffMetaStyle.Foreground(highlight)
+	commitLogSelectedStyle = commitLogSelectedStyle.Foreground(selectedFg).Background(selectedBg).Reverse(t.Reverse)
+
+	// Commit modal
+	modalStyle = modalStyle.BorderForeground(primary)
+	modalTitleStyle = modalTitleStyle.Foreground(accent)
+	labelStyle = labelStyle.Foreground(muted)
+	buttonStyle = buttonStyle.Foreground(buttonFg).Background(primary)
+	buttonActiveStyle = buttonActiveStyle.Foreground(buttonFg).Background(accent).Reverse(t.Reverse)
+	modalHelpStyle = modalHelpStyle.Foreground(muted)
+	hookOutputStyle = hookOutputStyle.BorderForeground(border)
+	policyErrorStyle = policyErrorStyle.Foreground(failure)
+	policyWarningStyle = policyWarningStyle.Foreground(warning)
+	signatureGoodStyle = signatureGoodStyle.Foreground(success)
+	signatureBadStyle = signatureBadStyle.Foreground(failure)
+	signatureUnverifiedStyle = signatureUnverifiedStyle.Foreground(warning)
+	signatureNoneStyle = signatureNoneStyle.Foreground(muted)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// withColors makes resolveTheme see a 256 color terminal without NO_COLOR
+func withColors(t *testing.T) {
+	t.Helper()
+	t.Setenv("NO_COLOR", "")
+	os.Unsetenv("NO_COLOR")
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+}
+
+func TestThemeInherit(t *testing.T) {
+	custom := Theme{Accent: "#ff0000", Error: "1"}
+	got := custom.inherit(builtinThemes["light"])
+
+	if got.Accent != "#ff0000" || got.Error != "1" {
+		t.Errorf("inherit overwrote set colors: %+v", got)
+	}
+	if got.Primary != builtinThemes["light"].Primary || got.ButtonFg != builtinThemes["light"].ButtonFg {
+		t.Errorf("inherit didn't fill unset colors: %+v", got)
+	}
+	if got := (Theme{}).inherit(builtinThemes["mono"]); !got.Reverse {
+		t.Error("inherit dropped reverse video from the base")
+	}
+}
+
+func TestResolveTheme(t *testing.T) {
+	withColors(t)
+	userThemes := map[string]Theme{
+		"mine":   {Accent: "1"},
+		"pastel": {Base: "light", Muted: "250"},
+		"broken": {Base: "sepia"},
+	}
+
+	if got, err := resolveTheme("high-contrast", userThemes); err != nil || got != builtinThemes["high-contrast"] {
+		t.Errorf("built-in theme: got %+v, %v", got, err)
+	}
+
+	got, err := resolveTheme("mine", userThemes)
+	if err != nil || got.Accent != "1" || got.Primary != builtinThemes["dark"].Primary {
+		t.Errorf("user theme should inherit from dark by default: got %+v, %v", got, err)
+	}
+
+	got, err = resolveTheme("pastel", userThemes)
+	if err != nil || got.Muted != "250" || got.Primary != builtinThemes["light"].Primary {
+		t.Errorf("user theme should inherit from its base: got %+v, %v", got, err)
+	}
+
+	if _, err := resolveTheme("broken", userThemes); err == nil {
+		t.Error("expected an error for an unknown base")
+	}
+	if _, err := resolveTheme("nope", userThemes); err == nil {
+		t.Error("expected an error for an unknown theme")
+	}
+}
+
+func TestResolveThemeNoColor(t *testing.T) {
+	withColors(t)
+	t.Setenv("NO_COLOR", "1")
+
+	if got, err := resolveTheme("dark", nil); err != nil || got != builtinThemes["mono"] {
+		t.Errorf("NO_COLOR should pick mono: got %+v, %v", got, err)
+	}
+}
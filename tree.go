@@ -0,0 +1,114 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// BranchTreeNode is a prefix group (e.g. "feature/") or a single branch in the tree view
+type BranchTreeNode struct {
+	Name       string // Last path segment
+	Path       string // Full prefix of a group, e.g. "user/alice"
+	Branch     *Branch
+	Children   []*BranchTreeNode
+	Count      int       // Branches under this node
+	LastUsed   time.Time // Most recent activity under this node
+	CommitDate time.Time
+	lead       *Branch // The branch listed first under this node, which places the node among its siblings
+}
+
+// branchPath returns the "/"-separated name of a branch without the remote marker
+func branchPath(branch Branch) string {
+	return strings.TrimSuffix(branch.Name, " (remote)")
+}
+
+// buildBranchTree groups branches by their "/" segments. Every level is ordered by the
+// sort order, with a group placed where its first branch would be.
+func buildBranchTree(branches []Branch, order SortOrder) *BranchTreeNode {
+	root := &BranchTreeNode{}
+
+	for i := range branches {
+		branch := &branches[i]
+		segments := strings.Split(branchPath(*branch), "/")
+
+		node := root
+		for depth, segment := range segments[:len(segments)-1] {
+			path := strings.Join(segments[:depth+1], "/")
+			var group *BranchTreeNode
+			for _, child := range node.Children {
+				if child.Branch == nil && child.Path == path {
+					group = child
+					break
+				}
+			}
+			if group == nil {
+				group = &BranchTreeNode{Name: segment, Path: path}
+				node.Children = append(node.Children, group)
+			}
+			node = group
+		}
+
+		leaf := strings.TrimPrefix(branch.Name, strings.Join(segments[:len(segments)-1], "/"))
+		node.Children = append(node.Children, &BranchTreeNode{
+			Name:   strings.TrimPrefix(leaf, "/"),
+			Branch: branch,
+		})
+	}
+
+	root.aggregate(order)
+	return root
+}
+
+// aggregate fills in counts and most recent activity bottom-up and sorts children by the sort order
+func (n *BranchTreeNode) aggregate(order SortOrder) {
+	if n.Branch != nil {
+		n.Count = 1
+		n.LastUsed = n.Branch.LastUsed
+		n.CommitDate = n.Branch.CommitDate
+		n.lead = n.Branch
+		return
+	}
+
+	n.Count = 0
+	for _, child := range n.Children {
+		child.aggregate(order)
+		n.Count += child.Count
+		if child.LastUsed.After(n.LastUsed) {
+			n.LastUsed = child.LastUsed
+		}
+		if child.CommitDate.After(n.CommitDate) {
+			n.CommitDate = child.CommitDate
+		}
+	}
+
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		return order.before(*n.Children[i].lead, *n.Children[j].lead)
+	})
+	if len(n.Children) > 0 {
+		n.lead = n.Children[0].lead
+	}
+}
+
+// tableRow is one visible row of the branch table: either a branch or a group header
+type tableRow struct {
+	branch *Branch
+	group  *BranchTreeNode
+	depth  int
+}
+
+// flatten lists the visible rows of the tree, skipping the contents of collapsed groups
+func (n *BranchTreeNode) flatten(collapsed map[string]bool, depth int) []tableRow {
+	var rows []tableRow
+	for _, child := range n.Children {
+		if child.Branch != nil {
+			rows = append(rows, tableRow{branch: child.Branch, depth: depth})
+			continue
+		}
+		rows = append(rows, tableRow{group: child, depth: depth})
+		if !collapsed[child.Path] {
+			rows = append(rows, child.flatten(collapsed, depth+1)...)
+		}
+	}
+	return rows
+}
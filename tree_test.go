@@ -0,0 +1,46 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// treeOrder lists the rows of a fully expanded tree as group paths and branch names
+func treeOrder(branches []Branch, order SortOrder) []string {
+	var names []string
+	for _, row := range buildBranchTree(branches, order).flatten(nil, 0) {
+		if row.group != nil {
+			names = append(names, row.group.Path+"/")
+		} else {
+			names = append(names, row.branch.Name)
+		}
+	}
+	return names
+}
+
+func TestBuildBranchTreeFollowsSortOrder(t *testing.T) {
+	now := time.Now()
+	branches := []Branch{
+		{Name: "main", LastUsed: now, AheadCount: 0},
+		{Name: "feature/b", LastUsed: now.Add(-time.Hour), AheadCount: 5},
+		{Name: "feature/a", LastUsed: now.Add(-2 * time.Hour), AheadCount: 1},
+		{Name: "zed", LastUsed: now.Add(-3 * time.Hour), AheadCount: 3},
+	}
+
+	tests := []struct {
+		order SortOrder
+		want  []string
+	}{
+		{SortOrder{Key: SortLastUsed, Descending: true}, []string{"main", "feature/", "feature/b", "feature/a", "zed"}},
+		{SortOrder{Key: SortLastUsed}, []string{"zed", "feature/", "feature/a", "feature/b", "main"}},
+		{SortOrder{Key: SortName}, []string{"feature/", "feature/a", "feature/b", "main", "zed"}},
+		{SortOrder{Key: SortName, Descending: true}, []string{"zed", "main", "feature/", "feature/b", "feature/a"}},
+		{SortOrder{Key: SortAhead, Descending: true}, []string{"feature/", "feature/b", "feature/a", "zed", "main"}},
+	}
+	for _, test := range tests {
+		if got := treeOrder(branches, test.order); !slices.Equal(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.order, got, test.want)
+		}
+	}
+}